@@ -1,8 +1,11 @@
 package bot
 
 import (
+	"context"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/bot/commands"
@@ -10,20 +13,26 @@ import (
 )
 
 type Bot struct {
-	session   *discordgo.Session
-	channelID string
-	userID    string
-	db        *db.DB
+	session *discordgo.Session
+	userID  string
+	db      *db.DB
 }
 
-// Setup initializes the bot and returns a cleanup function
-func Setup(s *discordgo.Session, channelID string, database *db.DB) (*Bot, func(), error) {
+// Setup initializes the bot and returns a cleanup function.
+// If legacyChannelID is set, games created before channel scoping are assigned to it.
+// Deleted games are purged once they have been deleted for longer than retention.
+func Setup(s *discordgo.Session, legacyChannelID string, retention time.Duration, database *db.DB) (*Bot, func(), error) {
 	// Initialize bot
 	bot := &Bot{
-		session:   s,
-		channelID: channelID,
-		userID:    s.State.User.ID,
-		db:        database,
+		session: s,
+		userID:  s.State.User.ID,
+		db:      database,
+	}
+
+	if legacyChannelID != "" {
+		if err := bot.claimUnscopedGames(legacyChannelID); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Register handlers
@@ -36,8 +45,11 @@ func Setup(s *discordgo.Session, channelID string, database *db.DB) (*Bot, func(
 		return nil, nil, err
 	}
 
+	stopPurger := bot.startPurger(retention)
+
 	// Return cleanup function
 	cleanup := func() {
+		stopPurger()
 		bot.cleanupCommands(registeredCommands)
 	}
 
@@ -50,19 +62,30 @@ func (b *Bot) handleMessageCreate(s *discordgo.Session, m *discordgo.MessageCrea
 		return
 	}
 
-	if m.ChannelID == b.channelID {
-		if strings.Contains(m.Content, "<@"+b.userID+">") {
-			s.ChannelMessageSend(m.ChannelID, "hello")
-		}
+	if m.GuildID != "" && strings.Contains(m.Content, "<@"+b.userID+">") {
+		s.ChannelMessageSend(m.ChannelID, "hello")
 	}
 }
 
-// handleInteractionCreate routes slash commands
+// handleInteractionCreate routes slash commands, autocomplete and message components
 func (b *Bot) handleInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.ChannelID != b.channelID {
+	// Games are scoped to guild channels, so ignore DMs
+	if i.GuildID == "" {
 		return
 	}
 
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleCommand(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		commands.HandleAutocomplete(s, i, b.db)
+	case discordgo.InteractionMessageComponent:
+		commands.HandleComponent(s, i, b.db)
+	}
+}
+
+// handleCommand routes /bingo subcommands to their handlers
+func (b *Bot) handleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	data := i.ApplicationCommandData()
 
 	if data.Name != commands.Prefix {
@@ -76,16 +99,44 @@ func (b *Bot) handleInteractionCreate(s *discordgo.Session, i *discordgo.Interac
 		commands.HandleNewGame(s, i, subCmd.Options, b.db)
 	case "delete_game":
 		commands.HandleDeleteGame(s, i, subCmd.Options, b.db)
+	case "restore_game":
+		commands.HandleRestoreGame(s, i, subCmd.Options, b.db)
 	case "set_active_game":
 		commands.HandleSetActiveGame(s, i, subCmd.Options, b.db)
+	case "set_consensus":
+		commands.HandleSetConsensus(s, i, subCmd.Options, b.db)
 	case "list_games":
 		commands.HandleListGames(s, i, subCmd.Options, b.db)
 	case "list_events":
 		commands.HandleListEvents(s, i, subCmd.Options, b.db)
 	case "view_board":
 		commands.HandleViewBoard(s, i, subCmd.Options, b.db)
+	case "my_board":
+		commands.HandleMyBoard(s, i, subCmd.Options, b.db)
+	case "verify_boards":
+		commands.HandleVerifyBoards(s, i, subCmd.Options, b.db)
+	case "add_player":
+		commands.HandleAddPlayer(s, i, subCmd.Options, b.db)
+	case "remove_player":
+		commands.HandleRemovePlayer(s, i, subCmd.Options, b.db)
 	case "vote":
 		commands.HandleVote(s, i, subCmd.Options, b.db)
+	case "unvote":
+		commands.HandleUnvote(s, i, subCmd.Options, b.db)
+	case "set_admin_role":
+		commands.HandleSetAdminRole(s, i, subCmd.Options, b.db)
+	case "set_game_state":
+		commands.HandleSetGameState(s, i, subCmd.Options, b.db)
+	case "set_voting":
+		commands.HandleSetVoting(s, i, subCmd.Options, b.db)
+	case "event":
+		commands.HandleEvent(s, i, subCmd.Options, b.db)
+	case "pack":
+		commands.HandlePack(s, i, subCmd.Options, b.db)
+	case "reopen_event":
+		commands.HandleReopenEvent(s, i, subCmd.Options, b.db)
+	case "event_panel":
+		commands.HandleEventPanel(s, i, subCmd.Options, b.db)
 	case "help":
 		commands.HandleHelp(s, i, subCmd.Options, b.db)
 	}
@@ -117,3 +168,22 @@ func (b *Bot) cleanupCommands(commands []*discordgo.ApplicationCommand) {
 		}
 	}
 }
+
+// claimUnscopedGames assigns games created before channel scoping to the given channel
+func (b *Bot) claimUnscopedGames(channelID string) error {
+	channel, err := b.session.Channel(channelID)
+	if err != nil {
+		return err
+	}
+	guildID, _ := strconv.ParseInt(channel.GuildID, 10, 64)
+	chanID, _ := strconv.ParseInt(channel.ID, 10, 64)
+
+	claimed, err := b.db.ClaimUnscopedGames(context.Background(), guildID, chanID)
+	if err != nil {
+		return err
+	}
+	if claimed > 0 {
+		log.Printf("Assigned %d unscoped games to channel %s", claimed, channelID)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// AddPlayer returns the add_player subcommand definition
+func AddPlayer() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "add_player",
+		Description: "Deal a board to a late joiner; closed events start marked (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "The Discord user joining the game",
+				Required:    true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleAddPlayer processes the add_player command
+func HandleAddPlayer(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	opt := findOption(options, "user")
+	if opt == nil {
+		respondError(s, i, "Missing required user option.")
+		return
+	}
+	userID := parseSnowflake(opt.UserValue(s).ID)
+
+	game, err := playerManagedGame(ctx, database, i, options, "add players")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	events, err := database.GetGameEvents(ctx, game.ID)
+	if err != nil {
+		respondError(s, i, "Error fetching events: "+err.Error())
+		return
+	}
+	dealtEvents := 0
+	if len(events) > 0 {
+		dealtEvents = events[len(events)-1].DisplayID
+	}
+
+	// The late board is dealt by the same rules as the rest, from a seed of its own
+	board := db.Board{GameID: game.ID, UserID: userID, GridSize: game.GridSize, Seed: rand.Int63(), DealtEvents: dealtEvents}
+	assignments := lateBoardDeal(game, events, userID, board.Seed, dealtEvents).boards()[userID]
+	_, err = database.AddPlayer(ctx, board, boardSquares(assignments))
+	if errors.Is(err, db.ErrAlreadyPlaying) {
+		respondError(s, i, fmt.Sprintf("<@%d> already has a board in game #%d.", userID, game.ID))
+		return
+	}
+	if err != nil {
+		respondError(s, i, "Error adding player: "+err.Error())
+		return
+	}
+
+	// Count marked squares as stored, after any retired events were swapped out
+	_, squares, err := database.GetUserBoard(ctx, game.ID, userID)
+	if err != nil {
+		respondError(s, i, "Error fetching board: "+err.Error())
+		return
+	}
+	marked := 0
+	for _, square := range squares {
+		if square.Marked() {
+			marked++
+		}
+	}
+
+	players, err := database.GetPlayerCountForGame(ctx, game.ID)
+	if err != nil {
+		respondError(s, i, "Error counting players: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Dealt a board to <@%d> in game #%d (**%s**).", userID, game.ID, game.Title)
+	if marked > 0 {
+		desc += fmt.Sprintf("\n%d of its squares are already marked; any pattern they complete is awarded when the next event closes.", marked)
+	}
+	desc += "\n" + describeThreshold(game, players)
+	respondEmbed(s, i, "Player Added", desc, colorSuccess, false)
+	refreshEventPanels(s, database, game.ID, 0)
+}
+
+// playerManagedGame resolves the selected or active game and checks the invoker may change its players
+func playerManagedGame(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, action string) (*db.Game, error) {
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		return nil, err
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching game: %w", err)
+	}
+	if err := checkGameManager(ctx, database, i, game, action); err != nil {
+		return nil, err
+	}
+	if err := checkEditable(game, action); err != nil {
+		return nil, err
+	}
+	if game.Lobby {
+		return nil, fmt.Errorf("game #%d is still in its lobby; players sign up with its Join button", game.ID)
+	}
+	return game, nil
+}
+
+// describeThreshold explains how many votes close an event now that the game has the given number of players
+func describeThreshold(game *db.Game, players int) string {
+	consensus, err := rules.ParseConsensus(game.Consensus)
+	if err != nil {
+		return ""
+	}
+	switch consensus.Kind {
+	case rules.ConsensusFixed, rules.ConsensusHost:
+		return fmt.Sprintf("Events still close with %s.", consensus.Describe())
+	case rules.ConsensusActive:
+		return fmt.Sprintf("Events close with %s, which follows who votes rather than who plays.", consensus.Describe())
+	}
+	return fmt.Sprintf("With %d players, events now close at %d votes (%s).", players, consensus.Threshold(rules.Tally{Players: players}), consensus.Describe())
+}
@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+const maxAutocompleteChoices = 25 // Discord's limit
+
+// HandleAutocomplete suggests values for the focused event_id, game_id, consensus, voting or pack option
+func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB) {
+	ctx := context.Background()
+
+	data := i.ApplicationCommandData()
+	if data.Name != Prefix || len(data.Options) == 0 {
+		return
+	}
+	subCmd := data.Options[0]
+	command := subCmd.Name
+	if subCmd.Type == discordgo.ApplicationCommandOptionSubCommandGroup {
+		if len(subCmd.Options) == 0 {
+			return
+		}
+		subCmd = subCmd.Options[0]
+		command += " " + subCmd.Name
+	}
+
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	for _, opt := range subCmd.Options {
+		if opt.Focused {
+			focused = opt
+			break
+		}
+	}
+	if focused == nil {
+		return
+	}
+	query := ""
+	if focused.Value != nil {
+		query = strings.TrimSpace(fmt.Sprint(focused.Value))
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	var err error
+	switch focused.Name {
+	case "event_id":
+		// Only closed events can be reopened and any live event edited; voting acts on open ones
+		statuses := []db.EventStatus{db.EventStatusOpen}
+		switch command {
+		case "reopen_event":
+			statuses = []db.EventStatus{db.EventStatusClosed}
+		case "event edit", "event retire":
+			statuses = []db.EventStatus{db.EventStatusOpen, db.EventStatusClosed}
+		}
+		choices, err = eventChoices(ctx, database, i, subCmd.Options, query, statuses...)
+	case "replacement":
+		choices, err = eventChoices(ctx, database, i, subCmd.Options, query, db.EventStatusOpen)
+	case "game_id":
+		choices, err = gameChoices(ctx, database, i, query, command == "restore_game")
+	case "consensus":
+		choices = consensusChoices(query)
+	case "voting":
+		choices = votingChoices(query)
+	case "pack", "name":
+		choices, err = packChoices(ctx, database, i, query)
+	}
+	if err != nil {
+		log.Printf("err %s actor=%s autocomplete %s: %v", interactionLabel(i), interactionActor(i), focused.Name, err)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+// eventChoices suggests events with one of the given statuses from the selected or active game
+func eventChoices(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, query string, statuses ...db.EventStatus) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	// A half-typed game_id arrives as a string, which getGameIDOrActive can't read
+	var gameOptions []*discordgo.ApplicationCommandInteractionDataOption
+	if opt := findOption(options, "game_id"); opt != nil {
+		if _, ok := opt.Value.(float64); !ok {
+			return nil, nil
+		}
+		gameOptions = options
+	}
+	gameID, err := getGameIDOrActive(ctx, database, i, gameOptions, "game_id")
+	if err != nil {
+		return nil, nil
+	}
+
+	events, err := database.GetGameEvents(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []scoredChoice
+	for _, event := range events {
+		if !slices.Contains(statuses, db.EventStatus(event.Status)) {
+			continue
+		}
+		id := strconv.Itoa(event.DisplayID)
+		score, ok := fuzzyScore(query, event.Description)
+		if strings.TrimPrefix(query, "#") == id {
+			score, ok = 1000, true
+		}
+		if !ok {
+			continue
+		}
+		matches = append(matches, scoredChoice{
+			score: score,
+			order: int64(event.DisplayID),
+			choice: &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(fmt.Sprintf("#%d %s", event.DisplayID, event.Description), 100),
+				Value: event.DisplayID,
+			},
+		})
+	}
+	return topChoices(matches), nil
+}
+
+// gameChoices suggests games in the current channel by title, or its deleted games
+func gameChoices(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, query string, deleted bool) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	list := database.ListGames
+	if deleted {
+		list = database.ListDeletedGames
+	}
+	games, err := list(ctx, parseSnowflake(i.ChannelID))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []scoredChoice
+	for n, game := range games {
+		score, ok := fuzzyScore(query, game.Title)
+		if strings.TrimPrefix(query, "#") == strconv.FormatInt(game.ID, 10) {
+			score, ok = 1000, true
+		}
+		if !ok {
+			continue
+		}
+		name := fmt.Sprintf("#%d %s", game.ID, game.Title)
+		if game.IsActive {
+			name += " (active)"
+		}
+		matches = append(matches, scoredChoice{
+			score: score,
+			order: int64(n), // keep list order: newest first, matching list_games
+			choice: &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(name, 100),
+				Value: game.ID,
+			},
+		})
+	}
+	return topChoices(matches), nil
+}
+
+// packChoices suggests the guild's saved event packs by name
+func packChoices(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, query string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	packs, err := database.ListPacks(ctx, parseSnowflake(i.GuildID))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []scoredChoice
+	for n, pack := range packs {
+		score, ok := fuzzyScore(query, pack.Name)
+		if !ok {
+			continue
+		}
+		matches = append(matches, scoredChoice{
+			score: score,
+			order: int64(n),
+			choice: &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(fmt.Sprintf("%s (%d events)", pack.Name, pack.EventCount), 100),
+				Value: pack.Name,
+			},
+		})
+	}
+	return topChoices(matches), nil
+}
+
+// consensusChoices suggests consensus rules, accepting whatever spec has been typed so far
+func consensusChoices(query string) []*discordgo.ApplicationCommandOptionChoice {
+	return specChoices(query, rules.ConsensusSpecs, func(spec string) (string, string, error) {
+		c, err := rules.ParseConsensus(spec)
+		return c.String(), c.Describe(), err
+	})
+}
+
+// votingChoices suggests voting eligibility rules, accepting whatever spec has been typed so far
+func votingChoices(query string) []*discordgo.ApplicationCommandOptionChoice {
+	return specChoices(query, rules.EligibilitySpecs, func(spec string) (string, string, error) {
+		e, err := rules.ParseEligibility(spec)
+		return e.String(), e.Describe(), err
+	})
+}
+
+// specChoices suggests rule specs matching query, led by the typed spec itself
+// when it parses. parse returns a spec's canonical form and description.
+func specChoices(query string, specs []string, parse func(string) (string, string, error)) []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	if canonical, desc, err := parse(query); err == nil && query != "" {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  canonical + " — " + desc,
+			Value: canonical,
+		})
+	}
+	for _, spec := range specs {
+		canonical, desc, _ := parse(spec)
+		if len(choices) > 0 && choices[0].Value == canonical {
+			continue
+		}
+		if _, ok := fuzzyScore(query, spec); ok {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  spec + " — " + desc,
+				Value: spec,
+			})
+		}
+	}
+	return choices
+}
+
+// findOption returns the named option, or nil if it was not supplied
+func findOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Name == name {
+			return opt
+		}
+	}
+	return nil
+}
+
+type scoredChoice struct {
+	score  int
+	order  int64
+	choice *discordgo.ApplicationCommandOptionChoice
+}
+
+// topChoices returns the best matches, highest score first and ties in natural order
+func topChoices(matches []scoredChoice) []*discordgo.ApplicationCommandOptionChoice {
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+		return matches[a].order < matches[b].order
+	})
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, min(len(matches), maxAutocompleteChoices))
+	for _, m := range matches[:min(len(matches), maxAutocompleteChoices)] {
+		choices = append(choices, m.choice)
+	}
+	return choices
+}
+
+// fuzzyScore rates how well text matches query. Substring matches beat in-order
+// subsequence matches, and matches at word starts beat matches mid-word.
+// An empty query matches everything equally.
+func fuzzyScore(query, text string) (int, bool) {
+	q := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	t := strings.ToLower(text)
+	if q == "" {
+		return 0, true
+	}
+
+	if idx := strings.Index(t, q); idx >= 0 {
+		score := 500 - min(utf8.RuneCountInString(t[:idx]), 100)
+		if isWordStart(t, idx) {
+			score += 200
+		}
+		return score, true
+	}
+
+	// Subsequence match: every non-space query rune appears in order
+	needle := []rune(strings.ReplaceAll(q, " ", ""))
+	score, qi, streak := 0, 0, 0
+	for idx, r := range t {
+		if qi == len(needle) {
+			break
+		}
+		if r != needle[qi] {
+			streak = 0
+			continue
+		}
+		score += 1 + streak
+		if isWordStart(t, idx) {
+			score += 5
+		}
+		streak++
+		qi++
+	}
+	if qi < len(needle) {
+		return 0, false
+	}
+	return score, true
+}
+
+// isWordStart reports whether the rune at byte offset idx begins a word
+func isWordStart(s string, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(s[:idx])
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
@@ -0,0 +1,574 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// boardDeal holds everything board generation depends on. Dealing the same
+// deal always produces the same boards, which is how verify_boards can prove
+// that stored boards came from the game's seed.
+type boardDeal struct {
+	Seed      int64
+	Events    []db.Event // in display ID order
+	PlayerIDs []int64    // in ascending order
+	GridSize  int
+	FreeCell  *rules.Cell
+	Mode      db.DealMode
+}
+
+// newBoardDeal builds a deal for a game's settings, sorting and de-duplicating players
+func newBoardDeal(game *db.Game, events []db.Event, playerIDs []int64) boardDeal {
+	players := slices.Clone(playerIDs)
+	slices.Sort(players)
+	return boardDeal{
+		Seed:      game.Seed,
+		Events:    events,
+		PlayerIDs: slices.Compact(players),
+		GridSize:  game.GridSize,
+		FreeCell:  game.FreeCell,
+		Mode:      db.DealMode(game.Dealing),
+	}
+}
+
+// lateBoardDeal builds the deal for a late joiner's board: a deal of its own,
+// seeded separately and using the game's events up to and including dealtEvents
+func lateBoardDeal(game *db.Game, events []db.Event, userID, seed int64, dealtEvents int) boardDeal {
+	var dealt []db.Event
+	for _, event := range events {
+		if event.DisplayID <= dealtEvents {
+			dealt = append(dealt, event)
+		}
+	}
+	d := newBoardDeal(game, dealt, []int64{userID})
+	d.Seed = seed
+	return d
+}
+
+// boards generates every player's board from a local RNG seeded with the deal's seed
+func (d boardDeal) boards() map[int64][]assignment {
+	rng := rand.New(rand.NewSource(d.Seed))
+	return distributeEvents(rng, d.Mode, d.Events, d.PlayerIDs, d.GridSize, d.FreeCell)
+}
+
+// createBoards stores the deal's boards, in player order, and reports how
+// fair they are
+func createBoards(ctx context.Context, tx *sql.Tx, database *db.DB, gameID int64, d boardDeal) (fairnessMetrics, error) {
+	boardAssignments := d.boards()
+	for _, playerID := range d.PlayerIDs {
+		boardID, err := database.CreateBoard(ctx, tx, gameID, playerID, d.GridSize)
+		if err != nil {
+			return fairnessMetrics{}, fmt.Errorf("error creating board: %w", err)
+		}
+
+		if err := database.CreateBoardSquares(ctx, tx, boardID, boardSquares(boardAssignments[playerID])); err != nil {
+			return fairnessMetrics{}, fmt.Errorf("error creating board squares: %w", err)
+		}
+	}
+	return measureFairness(boardAssignments, d.Events), nil
+}
+
+// boardSquares converts a board's assignments into squares to store
+func boardSquares(assignments []assignment) []db.BoardSquare {
+	squares := make([]db.BoardSquare, 0, len(assignments))
+	for _, assign := range assignments {
+		square := db.BoardSquare{
+			Row:     assign.row,
+			Column:  assign.col,
+			Kind:    db.SquareKindEvent,
+			EventID: assign.eventID,
+		}
+		if assign.free {
+			square.Kind = db.SquareKindFree
+		}
+		squares = append(squares, square)
+	}
+	return squares
+}
+
+type assignment struct {
+	eventID int64
+	row     int
+	col     int
+	free    bool // the game's free cell, with no event
+}
+
+// distributeEvents deals events onto one board per player, leaving the free
+// cell (if any) without an event. Every event is used at least once when the
+// boards have room, heavier events land on proportionally more boards, no
+// board repeats an event, and each board mixes as many categories as it can.
+// Fair dealing also evens out how often each event is used, then swaps
+// squares between boards to limit overlap and balance difficulty.
+func distributeEvents(rng *rand.Rand, mode db.DealMode, events []db.Event, playerIDs []int64, gridSize int, free *rules.Cell) map[int64][]assignment {
+	cellsPerBoard := gridSize * gridSize
+	if free != nil {
+		cellsPerBoard--
+	}
+	var quotas []int
+	if mode == db.DealFair && len(events) <= cellsPerBoard*len(playerIDs) {
+		quotas = fairQuotas(events, cellsPerBoard*len(playerIDs), len(playerIDs))
+	} else {
+		quotas = eventQuotas(rng, events, cellsPerBoard*len(playerIDs), len(playerIDs))
+	}
+
+	type board struct {
+		events     []db.Event
+		has        map[int64]bool
+		categories map[string]int
+	}
+	boards := make([]*board, len(playerIDs))
+	for i := range boards {
+		boards[i] = &board{has: make(map[int64]bool), categories: make(map[string]int)}
+	}
+	place := func(b *board, event db.Event) {
+		b.events = append(b.events, event)
+		b.has[event.ID] = true
+		if event.Category != "" {
+			b.categories[event.Category]++
+		}
+	}
+
+	// Place the most widely used events first, each on the open boards with the
+	// fewest squares from its category, then the fewest squares overall
+	order := rng.Perm(len(events))
+	sort.SliceStable(order, func(a, b int) bool { return quotas[order[a]] > quotas[order[b]] })
+	for _, idx := range order {
+		event := events[idx]
+		candidates := rng.Perm(len(boards))
+		sort.SliceStable(candidates, func(a, b int) bool {
+			ba, bb := boards[candidates[a]], boards[candidates[b]]
+			if ca, cb := ba.categories[event.Category], bb.categories[event.Category]; event.Category != "" && ca != cb {
+				return ca < cb
+			}
+			return len(ba.events) < len(bb.events)
+		})
+		placed := 0
+		for _, c := range candidates {
+			if placed == quotas[idx] {
+				break
+			}
+			if b := boards[c]; len(b.events) < cellsPerBoard && !b.has[event.ID] {
+				place(b, event)
+				placed++
+			}
+		}
+	}
+
+	// Top up any board the greedy pass left short
+	for _, b := range boards {
+		for _, idx := range rng.Perm(len(events)) {
+			if len(b.events) == cellsPerBoard {
+				break
+			}
+			if !b.has[events[idx].ID] {
+				place(b, events[idx])
+			}
+		}
+	}
+
+	if mode == db.DealFair {
+		lists := make([][]db.Event, len(boards))
+		for i, b := range boards {
+			lists[i] = b.events
+		}
+		balanceBoards(rng, lists)
+	}
+
+	assignments := make(map[int64][]assignment, len(playerIDs))
+	for i, pid := range playerIDs {
+		assignments[pid] = layoutBoard(rng, boards[i].events, gridSize, free)
+	}
+	return assignments
+}
+
+// eventQuotas decides how many boards each event appears on. The quotas add up
+// to totalCells and never exceed maxPerEvent (one per board). If there are more
+// events than cells, a weighted sample of events is used once each.
+func eventQuotas(rng *rand.Rand, events []db.Event, totalCells, maxPerEvent int) []int {
+	quotas := make([]int, len(events))
+	weight := func(i int) float64 { return eventWeight(events[i]) }
+
+	// pick draws an index with probability proportional to weight among those
+	// that can still take another board
+	pick := func(limit int) int {
+		total := 0.0
+		for i := range events {
+			if quotas[i] < limit {
+				total += weight(i)
+			}
+		}
+		if total == 0 {
+			return -1
+		}
+		r := rng.Float64() * total
+		last := -1
+		for i := range events {
+			if quotas[i] >= limit {
+				continue
+			}
+			last = i
+			if r -= weight(i); r < 0 {
+				return i
+			}
+		}
+		return last
+	}
+
+	remaining := totalCells
+	if len(events) > totalCells {
+		for ; remaining > 0; remaining-- {
+			quotas[pick(1)]++
+		}
+		return quotas
+	}
+
+	for i := range quotas {
+		quotas[i] = 1
+	}
+	for remaining -= len(events); remaining > 0; remaining-- {
+		i := pick(maxPerEvent)
+		if i < 0 {
+			break
+		}
+		quotas[i]++
+	}
+	return quotas
+}
+
+// eventWeight is an event's weight, treating unset weights as 1
+func eventWeight(event db.Event) float64 {
+	if event.Weight <= 0 {
+		return 1
+	}
+	return event.Weight
+}
+
+// fairQuotas apportions totalCells between events in proportion to their
+// weights, using each event at least once and never more than maxPerEvent
+// times. Unlike eventQuotas nothing is left to chance, so events of equal
+// weight are used within one board of each other.
+func fairQuotas(events []db.Event, totalCells, maxPerEvent int) []int {
+	total := 0.0
+	for _, event := range events {
+		total += eventWeight(event)
+	}
+
+	quotas := make([]int, len(events))
+	for i := range quotas {
+		quotas[i] = 1
+	}
+	for remaining := totalCells - len(events); remaining > 0; remaining-- {
+		// Give the next board to the event furthest below its proportional share
+		best, bestGap := -1, 0.0
+		for i, event := range events {
+			if quotas[i] >= maxPerEvent {
+				continue
+			}
+			gap := float64(totalCells)*eventWeight(event)/total - float64(quotas[i])
+			if best < 0 || gap > bestGap {
+				best, bestGap = i, gap
+			}
+		}
+		if best < 0 {
+			break
+		}
+		quotas[best]++
+	}
+	return quotas
+}
+
+// Relative importance of each imbalance that balanceBoards reduces
+const (
+	overlapCost    = 1.0 // per squared count of squares two boards share
+	difficultyCost = 4.0 // per squared difference from the mean board weight
+	categoryCost   = 2.0 // per squared difference from a category's even share
+)
+
+// balanceIterations is how many swaps balanceBoards tries per square
+const balanceIterations = 200
+
+// balanceBoards improves a deal by swapping events between pairs of boards,
+// keeping only swaps that lower the combined cost of pairwise overlap, uneven
+// board difficulty and uneven category spread. Weights double as how likely an
+// event is, so a board's total weight stands for how easy it is. Swaps keep
+// every event's usage count, and the search is driven by rng so it is
+// reproducible from the game's seed.
+func balanceBoards(rng *rand.Rand, boards [][]db.Event) {
+	if len(boards) < 2 {
+		return
+	}
+
+	index := make(map[int64]int)
+	for _, board := range boards {
+		for _, event := range board {
+			if _, ok := index[event.ID]; !ok {
+				index[event.ID] = len(index)
+			}
+		}
+	}
+	has := make([][]bool, len(boards))
+	weights := make([]float64, len(boards))
+	categories := make([]map[string]int, len(boards))
+	totals := make(map[string]int)
+	mean := 0.0
+	for b, board := range boards {
+		has[b] = make([]bool, len(index))
+		categories[b] = make(map[string]int)
+		for _, event := range board {
+			has[b][index[event.ID]] = true
+			weights[b] += eventWeight(event)
+			if event.Category != "" {
+				categories[b][event.Category]++
+				totals[event.Category]++
+			}
+		}
+		mean += weights[b]
+	}
+	mean /= float64(len(boards))
+
+	shared := make([][]int, len(boards))
+	for b := range boards {
+		shared[b] = make([]int, len(boards))
+		for c := range boards {
+			for e := range index {
+				if has[b][index[e]] && has[c][index[e]] {
+					shared[b][c]++
+				}
+			}
+		}
+	}
+
+	square := func(x float64) float64 { return x * x }
+	categoryDelta := func(b int, category string, change int) float64 {
+		if category == "" {
+			return 0
+		}
+		share := float64(totals[category]) / float64(len(boards))
+		count := float64(categories[b][category])
+		return categoryCost * (square(count+float64(change)-share) - square(count-share))
+	}
+
+	iterations := balanceIterations * len(boards) * len(boards[0])
+	for range iterations {
+		b1, b2 := rng.Intn(len(boards)), rng.Intn(len(boards))
+		if b1 == b2 || len(boards[b1]) == 0 || len(boards[b2]) == 0 {
+			continue
+		}
+		p1, p2 := rng.Intn(len(boards[b1])), rng.Intn(len(boards[b2]))
+		e1, e2 := boards[b1][p1], boards[b2][p2]
+		i1, i2 := index[e1.ID], index[e2.ID]
+		if i1 == i2 || has[b2][i1] || has[b1][i2] {
+			continue
+		}
+
+		// Moving e1 to b2 and e2 to b1 leaves b1 and b2's shared squares alone,
+		// but changes what each shares with every other board
+		delta := 0.0
+		for c := range boards {
+			if c == b1 || c == b2 {
+				continue
+			}
+			d := 0
+			if has[c][i2] {
+				d++
+			}
+			if has[c][i1] {
+				d--
+			}
+			delta += overlapCost * (square(float64(shared[b1][c]+d)) - square(float64(shared[b1][c])))
+			delta += overlapCost * (square(float64(shared[b2][c]-d)) - square(float64(shared[b2][c])))
+		}
+		w1, w2 := eventWeight(e1), eventWeight(e2)
+		delta += difficultyCost * (square(weights[b1]-w1+w2-mean) - square(weights[b1]-mean))
+		delta += difficultyCost * (square(weights[b2]-w2+w1-mean) - square(weights[b2]-mean))
+		if e1.Category != e2.Category {
+			delta += categoryDelta(b1, e1.Category, -1) + categoryDelta(b1, e2.Category, 1)
+			delta += categoryDelta(b2, e2.Category, -1) + categoryDelta(b2, e1.Category, 1)
+		}
+		if delta >= 0 {
+			continue
+		}
+
+		// Apply the swap
+		for c := range boards {
+			if c == b1 || c == b2 {
+				continue
+			}
+			d := 0
+			if has[c][i2] {
+				d++
+			}
+			if has[c][i1] {
+				d--
+			}
+			shared[b1][c] += d
+			shared[c][b1] += d
+			shared[b2][c] -= d
+			shared[c][b2] -= d
+		}
+		if e1.Category != "" {
+			categories[b1][e1.Category]--
+			categories[b2][e1.Category]++
+		}
+		if e2.Category != "" {
+			categories[b2][e2.Category]--
+			categories[b1][e2.Category]++
+		}
+		weights[b1] += w2 - w1
+		weights[b2] += w1 - w2
+		has[b1][i1], has[b1][i2] = false, true
+		has[b2][i2], has[b2][i1] = false, true
+		boards[b1][p1], boards[b2][p2] = e2, e1
+	}
+}
+
+// fairnessMetrics summarises how evenly a deal treats players
+type fairnessMetrics struct {
+	maxOverlap       int     // most squares any two boards share
+	avgOverlap       float64 // mean squares shared per pair of boards
+	minUses, maxUses int     // fewest and most boards any dealt event is on
+	difficultySpread float64 // range of board weight totals, as a fraction of the mean
+}
+
+// measureFairness computes fairness metrics for dealt boards
+func measureFairness(boards map[int64][]assignment, events []db.Event) fairnessMetrics {
+	weights := make(map[int64]float64, len(events))
+	for _, event := range events {
+		weights[event.ID] = eventWeight(event)
+	}
+
+	var m fairnessMetrics
+	uses := make(map[int64]int)
+	sets := make([]map[int64]bool, 0, len(boards))
+	totals := make([]float64, 0, len(boards))
+	for _, board := range boards {
+		set := make(map[int64]bool, len(board))
+		total := 0.0
+		for _, assign := range board {
+			if assign.free {
+				continue
+			}
+			set[assign.eventID] = true
+			uses[assign.eventID]++
+			total += weights[assign.eventID]
+		}
+		sets = append(sets, set)
+		totals = append(totals, total)
+	}
+
+	pairs, sharedTotal := 0, 0
+	for a := range sets {
+		for b := a + 1; b < len(sets); b++ {
+			shared := 0
+			for id := range sets[a] {
+				if sets[b][id] {
+					shared++
+				}
+			}
+			m.maxOverlap = max(m.maxOverlap, shared)
+			sharedTotal += shared
+			pairs++
+		}
+	}
+	if pairs > 0 {
+		m.avgOverlap = float64(sharedTotal) / float64(pairs)
+	}
+
+	first := true
+	for _, n := range uses {
+		if first || n < m.minUses {
+			m.minUses = n
+		}
+		if first || n > m.maxUses {
+			m.maxUses = n
+		}
+		first = false
+	}
+
+	if len(totals) > 0 {
+		lo, hi, sum := totals[0], totals[0], 0.0
+		for _, t := range totals {
+			lo, hi, sum = min(lo, t), max(hi, t), sum+t
+		}
+		if sum > 0 {
+			m.difficultySpread = (hi - lo) / (sum / float64(len(totals)))
+		}
+	}
+	return m
+}
+
+// String describes the metrics for the new_game response
+func (m fairnessMetrics) String() string {
+	return fmt.Sprintf("boards share at most %d squares (avg %.1f) | events used %d–%d times | difficulty spread %.0f%%",
+		m.maxOverlap, m.avgOverlap, m.minUses, m.maxUses, m.difficultySpread*100)
+}
+
+// layoutBoard arranges a board's events row by row around the free cell, if
+// any, keeping squares of the same category from sitting next to each other
+// where possible
+func layoutBoard(rng *rand.Rand, events []db.Event, gridSize int, free *rules.Cell) []assignment {
+	byCategory := make(map[string][]db.Event)
+	var categories []string
+	for _, idx := range rng.Perm(len(events)) {
+		event := events[idx]
+		if _, ok := byCategory[event.Category]; !ok {
+			categories = append(categories, event.Category)
+		}
+		byCategory[event.Category] = append(byCategory[event.Category], event)
+	}
+
+	grid := make([]string, 0, len(events))
+	conflicts := func(category string) bool {
+		if category == "" {
+			return false
+		}
+		n := len(grid)
+		left := n%gridSize > 0 && grid[n-1] == category
+		up := n >= gridSize && grid[n-gridSize] == category
+		return left || up
+	}
+
+	result := make([]assignment, 0, gridSize*gridSize)
+	for len(grid) < gridSize*gridSize {
+		n := len(grid)
+		if free != nil && n == free.Row*gridSize+free.Col {
+			result = append(result, assignment{free: true, row: free.Row, col: free.Col})
+			grid = append(grid, "")
+			continue
+		}
+
+		// Prefer the category with the most squares left so it doesn't bunch up at the end
+		sort.SliceStable(categories, func(a, b int) bool {
+			return len(byCategory[categories[a]]) > len(byCategory[categories[b]])
+		})
+		chosen := ""
+		found := false
+		for _, category := range categories {
+			if len(byCategory[category]) > 0 && !conflicts(category) {
+				chosen, found = category, true
+				break
+			}
+		}
+		if !found {
+			for _, category := range categories {
+				if len(byCategory[category]) > 0 {
+					chosen = category
+					break
+				}
+			}
+		}
+
+		event := byCategory[chosen][0]
+		byCategory[chosen] = byCategory[chosen][1:]
+		result = append(result, assignment{eventID: event.ID, row: n / gridSize, col: n % gridSize})
+		grid = append(grid, chosen)
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// testEvents returns n events with display IDs 1..n spread over three categories
+func testEvents(n int) []db.Event {
+	events := make([]db.Event, n)
+	for i := range events {
+		events[i] = db.Event{
+			ID:          int64(i + 1),
+			DisplayID:   i + 1,
+			Description: fmt.Sprintf("Event %d", i+1),
+			Status:      string(db.EventStatusOpen),
+			Category:    fmt.Sprintf("cat%d", i%3),
+			Weight:      float64(1 + i%2),
+		}
+	}
+	return events
+}
+
+func TestBoardDeal(t *testing.T) {
+	center := &rules.Cell{Row: 2, Col: 2}
+	tests := []struct {
+		name   string
+		mode   db.DealMode
+		free   *rules.Cell
+		events int
+	}{
+		{"standard", db.DealStandard, nil, 30},
+		{"standard free cell", db.DealStandard, center, 30},
+		{"fair", db.DealFair, nil, 30},
+		{"fair free cell", db.DealFair, center, 30},
+		{"more events than squares", db.DealFair, nil, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := &db.Game{Seed: 42, GridSize: 5, FreeCell: tt.free, Dealing: string(tt.mode)}
+			// Player order and duplicates must not change the deal
+			d := newBoardDeal(game, testEvents(tt.events), []int64{30, 10, 20, 10})
+			boards := d.boards()
+
+			if again := newBoardDeal(game, testEvents(tt.events), []int64{10, 20, 30}).boards(); !reflect.DeepEqual(boards, again) {
+				t.Fatal("dealing the same seed twice gave different boards")
+			}
+			reseeded := *game
+			reseeded.Seed = 43
+			if other := newBoardDeal(&reseeded, testEvents(tt.events), []int64{10, 20, 30}).boards(); reflect.DeepEqual(boards, other) {
+				t.Error("a different seed gave the same boards")
+			}
+
+			if len(boards) != 3 {
+				t.Fatalf("dealt %d boards, want 3", len(boards))
+			}
+			for playerID, assignments := range boards {
+				if len(assignments) != 25 {
+					t.Errorf("player %d has %d squares, want 25", playerID, len(assignments))
+				}
+				seen := make(map[int64]bool)
+				for _, a := range assignments {
+					isFree := tt.free != nil && a.row == tt.free.Row && a.col == tt.free.Col
+					if a.free != isFree {
+						t.Errorf("player %d square (%d,%d) free = %t, want %t", playerID, a.row, a.col, a.free, isFree)
+					}
+					if a.free {
+						continue
+					}
+					if seen[a.eventID] {
+						t.Errorf("player %d has event %d twice", playerID, a.eventID)
+					}
+					seen[a.eventID] = true
+				}
+			}
+		})
+	}
+}
+
+func TestLateBoardDeal(t *testing.T) {
+	game := &db.Game{Seed: 42, GridSize: 3, Dealing: string(db.DealStandard)}
+	events := testEvents(20)
+	boards := lateBoardDeal(game, events, 99, 7, 9).boards()
+	for _, a := range boards[99] {
+		if a.eventID > 9 {
+			t.Errorf("late board uses event %d, dealt after the board's %d events", a.eventID, 9)
+		}
+	}
+	if again := lateBoardDeal(game, events, 99, 7, 9).boards(); !reflect.DeepEqual(boards, again) {
+		t.Error("dealing the same late board twice gave different boards")
+	}
+}
+
+func TestVerifyBoards(t *testing.T) {
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "bingo.db"))
+	database, err := db.InitDB()
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+
+	events := testEvents(12)
+	game := db.Game{
+		GuildID:     1,
+		ChannelID:   2,
+		Title:       "verify",
+		GridSize:    3,
+		WinPattern:  rules.DefaultPattern,
+		PrizePlaces: 1,
+		Consensus:   rules.DefaultConsensus,
+		HostID:      10,
+		Eligibility: rules.DefaultEligibility,
+		State:       string(db.GameStateRunning),
+		Seed:        1234,
+		DealtEvents: len(events),
+		Dealing:     string(db.DealFair),
+	}
+	if game.ID, err = database.CreateGame(ctx, game); err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	err = database.WithTx(ctx, func(tx *sql.Tx) error {
+		for i := range events {
+			events[i].GameID = game.ID
+			if events[i].ID, err = database.CreateEvent(ctx, tx, events[i]); err != nil {
+				return err
+			}
+		}
+		_, err := createBoards(ctx, tx, database, game.ID, newBoardDeal(&game, events, []int64{10, 20}))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("dealing boards: %v", err)
+	}
+
+	report, err := verifyBoards(ctx, database, &game)
+	if err != nil {
+		t.Fatalf("verifyBoards: %v", err)
+	}
+	if report.boards != 2 || report.mismatches != 0 {
+		t.Fatalf("verifyBoards on untouched boards = %d boards, %d mismatches, want 2 and 0", report.boards, report.mismatches)
+	}
+
+	// Copy one square's event onto its neighbour, as if the board had been edited
+	boards, err := database.GetGameBoards(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetGameBoards: %v", err)
+	}
+	err = database.WithTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE board_squares SET event_id = (SELECT event_id FROM board_squares WHERE board_id = ? AND row = 0 AND column = 1)
+			 WHERE board_id = ? AND row = 0 AND column = 0`, boards[0].ID, boards[0].ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("tampering with a square: %v", err)
+	}
+
+	report, err = verifyBoards(ctx, database, &game)
+	if err != nil {
+		t.Fatalf("verifyBoards: %v", err)
+	}
+	if report.mismatches != 1 || len(report.lines) != 1 {
+		t.Errorf("verifyBoards after tampering = %d mismatches (%v), want 1", report.mismatches, report.lines)
+	}
+}
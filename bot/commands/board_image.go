@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"image/color"
 	"image/png"
+	"math"
 	"strings"
 
 	"github.com/fogleman/gg"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
 )
 
 const (
@@ -25,10 +27,27 @@ var (
 	colorCompleted = color.RGBA{76, 175, 80, 255}   // green for completed cells
 	colorBorder    = color.RGBA{60, 60, 60, 255}    // dark grey border
 	colorText      = color.RGBA{33, 33, 33, 255}    // dark text
+	colorTarget    = color.RGBA{241, 196, 15, 255}  // gold outline for squares the win pattern needs
+	colorFree      = color.RGBA{100, 181, 246, 255} // blue for free squares
+	colorStrike    = color.NRGBA{198, 40, 40, 220}  // red strike-through for completed winning lines
+	colorOneAway   = color.NRGBA{255, 152, 0, 90}   // translucent orange band for lines one square from done
+	colorRecent    = color.RGBA{156, 39, 176, 255}  // purple ring for the most recently closed square
 )
 
-// GenerateBoardImage creates a PNG image of the bingo board in memory
-func GenerateBoardImage(grid [][]db.BoardSquareWithEvent, gridSize int) ([]byte, error) {
+// BoardOverlay marks up a board image beyond its open and closed squares
+type BoardOverlay struct {
+	Won     [][]rules.Cell // completed lines that count toward the pattern, struck through
+	OneAway [][]rules.Cell // lines missing a single square, tinted
+	Recent  *rules.Cell    // the most recently closed square, highlighted
+}
+
+// freeLabel is drawn on free squares in place of an event description
+var freeLabel = []string{"★", "FREE"}
+
+// GenerateBoardImage creates a PNG image of the bingo board in memory.
+// Squares required by fixed-shape win patterns are outlined, and the overlay
+// is drawn on top.
+func GenerateBoardImage(grid [][]db.BoardSquareWithEvent, gridSize int, pattern rules.Pattern, overlay BoardOverlay) ([]byte, error) {
 	// Calculate canvas size
 	width := gridSize*cellSize + 2*padding
 	height := gridSize*cellSize + 2*padding
@@ -48,6 +67,48 @@ func GenerateBoardImage(grid [][]db.BoardSquareWithEvent, gridSize int) ([]byte,
 		}
 	}
 
+	// Tint lines that need one more square, under the outlines
+	dc.SetColor(colorOneAway)
+	dc.SetLineCapButt()
+	dc.SetLineWidth(cellSize * 0.6)
+	for _, line := range overlay.OneAway {
+		drawLine(dc, line, cellSize*0.4)
+	}
+
+	// Outline the pattern's target squares
+	if target := pattern.Target(gridSize); target != nil {
+		dc.SetColor(colorTarget)
+		dc.SetLineWidth(6)
+		for row := 0; row < gridSize; row++ {
+			for col := 0; col < gridSize; col++ {
+				if target[row][col] {
+					x := float64(col*cellSize + padding)
+					y := float64(row*cellSize + padding)
+					dc.DrawRectangle(x+4, y+4, cellSize-8, cellSize-8)
+					dc.Stroke()
+				}
+			}
+		}
+	}
+
+	// Ring the latest square to be marked
+	if overlay.Recent != nil {
+		dc.SetColor(colorRecent)
+		dc.SetLineWidth(6)
+		x := float64(overlay.Recent.Col*cellSize + padding)
+		y := float64(overlay.Recent.Row*cellSize + padding)
+		dc.DrawRectangle(x+12, y+12, cellSize-24, cellSize-24)
+		dc.Stroke()
+	}
+
+	// Strike through completed lines, past the end squares' centres
+	dc.SetColor(colorStrike)
+	dc.SetLineCapRound()
+	dc.SetLineWidth(8)
+	for _, line := range overlay.Won {
+		drawLine(dc, line, cellSize*0.35)
+	}
+
 	// Encode to PNG
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, dc.Image()); err != nil {
@@ -57,13 +118,35 @@ func GenerateBoardImage(grid [][]db.BoardSquareWithEvent, gridSize int) ([]byte,
 	return buf.Bytes(), nil
 }
 
+// drawLine strokes from the centre of a line's first square to the centre of
+// its last, extended by overhang pixels at each end
+func drawLine(dc *gg.Context, line []rules.Cell, overhang float64) {
+	if len(line) == 0 {
+		return
+	}
+	center := func(c rules.Cell) (float64, float64) {
+		return float64(c.Col*cellSize+padding) + cellSize/2, float64(c.Row*cellSize+padding) + cellSize/2
+	}
+	x1, y1 := center(line[0])
+	x2, y2 := center(line[len(line)-1])
+	if length := math.Hypot(x2-x1, y2-y1); length > 0 {
+		dx, dy := (x2-x1)/length*overhang, (y2-y1)/length*overhang
+		x1, y1, x2, y2 = x1-dx, y1-dy, x2+dx, y2+dy
+	}
+	dc.DrawLine(x1, y1, x2, y2)
+	dc.Stroke()
+}
+
 // drawCell renders a single cell with background color and text
 func drawCell(dc *gg.Context, row, col int, sq db.BoardSquareWithEvent) {
 	x := float64(col*cellSize + padding)
 	y := float64(row*cellSize + padding)
 
 	// Draw background
-	if sq.EventStatus == string(db.EventStatusClosed) {
+	free := sq.Kind == db.SquareKindFree
+	if free {
+		dc.SetColor(colorFree)
+	} else if sq.Marked() {
 		dc.SetColor(colorCompleted)
 	} else {
 		dc.SetColor(colorOpen)
@@ -77,23 +160,30 @@ func drawCell(dc *gg.Context, row, col int, sq db.BoardSquareWithEvent) {
 	dc.DrawRectangle(x, y, cellSize, cellSize)
 	dc.Stroke()
 
-	// Draw text
+	// Draw text, larger for the free label
+	size := fontSize
+	if free {
+		size = fontSize * 2
+	}
 	dc.SetColor(colorText)
-	if err := dc.LoadFontFace("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", fontSize); err != nil {
+	if err := dc.LoadFontFace("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", size); err != nil {
 		// Fallback if font not found - try common alternatives
-		dc.LoadFontFace("/usr/share/fonts/truetype/liberation/LiberationSans-Regular.ttf", fontSize)
+		dc.LoadFontFace("/usr/share/fonts/truetype/liberation/LiberationSans-Regular.ttf", size)
 	}
 
 	// Wrap text into lines
-	lines := wrapText(dc, sq.EventDescription, cellSize-20) // 20px margin
+	lines := freeLabel
+	if !free {
+		lines = wrapText(dc, sq.EventDescription, cellSize-20) // 20px margin
+	}
 
 	// Center text vertically
-	textHeight := float64(len(lines)) * fontSize * lineSpacing
-	startY := y + (cellSize-textHeight)/2 + fontSize
+	textHeight := float64(len(lines)) * size * lineSpacing
+	startY := y + (cellSize-textHeight)/2 + size
 
 	// Draw each line centered
 	for i, line := range lines {
-		lineY := startY + float64(i)*fontSize*lineSpacing
+		lineY := startY + float64(i)*size*lineSpacing
 		lineWidth, _ := dc.MeasureString(line)
 		lineX := x + (cellSize-lineWidth)/2
 		dc.DrawString(line, lineX, lineY)
@@ -4,20 +4,41 @@ import "github.com/bwmarrin/discordgo"
 
 const Prefix = "bingo"
 
+// defaultMemberPermissions lets anyone who can chat use the command by default;
+// server admins can narrow it per role or channel in Discord's Integrations settings.
+// Game management is further limited to hosts and admins by the handlers.
+var defaultMemberPermissions int64 = discordgo.PermissionSendMessages
+
 // All returns all command definitions assembled into the /{Prefix} command
 func All() []*discordgo.ApplicationCommand {
 	return []*discordgo.ApplicationCommand{
 		{
-			Name:        Prefix,
-			Description: "Bingo game commands",
+			Name:                     Prefix,
+			Description:              "Bingo game commands",
+			DefaultMemberPermissions: &defaultMemberPermissions,
+			DMPermission:             boolPtr(false),
 			Options: []*discordgo.ApplicationCommandOption{
 				NewGame(),
 				DeleteGame(),
+				RestoreGame(),
 				SetActiveGame(),
+				SetConsensus(),
+				SetVoting(),
+				SetGameState(),
+				SetAdminRole(),
 				ListGames(),
 				ListEvents(),
 				ViewBoard(),
+				MyBoard(),
+				VerifyBoards(),
+				AddPlayer(),
+				RemovePlayer(),
 				Vote(),
+				Unvote(),
+				ReopenEvent(),
+				Event(),
+				Pack(),
+				EventPanel(),
 				Help(),
 			},
 		},
@@ -28,3 +49,8 @@ func All() []*discordgo.ApplicationCommand {
 func floatPtr(f float64) *float64 {
 	return &f
 }
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
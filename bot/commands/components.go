@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// Component actions, encoded in custom IDs as "{Prefix}:{action}:{args...}"
+const (
+	componentPanelVote = "panel_vote"
+	componentPanelPage = "panel_page"
+
+	componentDeleteConfirm = "delete_confirm"
+	componentDeleteCancel  = "delete_cancel"
+
+	componentLobbyJoin  = "lobby_join"
+	componentLobbyLeave = "lobby_leave"
+	componentLobbyStart = "lobby_start"
+)
+
+// componentID builds a message component custom ID
+func componentID(action string, args ...any) string {
+	parts := []string{Prefix, action}
+	for _, arg := range args {
+		parts = append(parts, fmt.Sprint(arg))
+	}
+	return strings.Join(parts, ":")
+}
+
+// parseComponentID splits a custom ID into its action and integer arguments
+func parseComponentID(customID string) (string, []int64, bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) < 2 || parts[0] != Prefix {
+		return "", nil, false
+	}
+	args := make([]int64, 0, len(parts)-2)
+	for _, part := range parts[2:] {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return "", nil, false
+		}
+		args = append(args, n)
+	}
+	return parts[1], args, true
+}
+
+// HandleComponent routes button and select menu interactions on bot messages
+func HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB) {
+	action, args, ok := parseComponentID(i.MessageComponentData().CustomID)
+	if !ok {
+		return
+	}
+
+	switch action {
+	case componentPanelVote:
+		if len(args) == 2 {
+			handlePanelVote(s, i, args[0], int(args[1]), database)
+		}
+	case componentPanelPage:
+		if len(args) == 2 {
+			handlePanelPage(s, i, args[0], int(args[1]), database)
+		}
+	case componentDeleteConfirm:
+		if len(args) == 1 {
+			handleDeleteConfirm(s, i, args[0], database)
+		}
+	case componentDeleteCancel:
+		if len(args) == 1 {
+			handleDeleteCancel(s, i, args[0])
+		}
+	case componentLobbyJoin:
+		if len(args) == 1 {
+			handleLobbyJoin(s, i, args[0], database)
+		}
+	case componentLobbyLeave:
+		if len(args) == 1 {
+			handleLobbyLeave(s, i, args[0], database)
+		}
+	case componentLobbyStart:
+		if len(args) == 1 {
+			handleLobbyStart(s, i, args[0], database)
+		}
+	}
+}
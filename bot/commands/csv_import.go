@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fordtom/bingo/db"
+)
+
+// CSV columns understood by the importer. Files with a header row may use any
+// subset of these in any order; files without one only read the first column.
+const (
+	columnDescription = "description"
+	columnCategory    = "category"
+	columnWeight      = "weight"
+	columnTags        = "tags"
+	columnFree        = "free"
+)
+
+var csvColumns = []string{columnDescription, columnCategory, columnWeight, columnTags, columnFree}
+
+const (
+	// maxEventWeight keeps one event from crowding everything else off the boards
+	maxEventWeight = 100
+	// maxImportErrors is how many row errors an import reports before summarising the rest
+	maxImportErrors = 10
+)
+
+// importError collects the problems found in an imported file, by CSV line or
+// game file path
+type importError struct {
+	lines []string
+}
+
+func (e *importError) add(line int, format string, args ...any) {
+	e.lines = append(e.lines, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+func (e *importError) addPath(path string, format string, args ...any) {
+	e.lines = append(e.lines, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (e *importError) Error() string {
+	shown := e.lines
+	more := ""
+	if len(shown) > maxImportErrors {
+		more = fmt.Sprintf("\n…and %d more", len(shown)-maxImportErrors)
+		shown = shown[:maxImportErrors]
+	}
+	return fmt.Sprintf("%d problem(s) found:\n%s%s", len(e.lines), strings.Join(shown, "\n"), more)
+}
+
+// fetchAndParseCSV fetches a CSV file from URL and parses its events
+func fetchAndParseCSV(url string) ([]db.Event, error) {
+	data, err := fetchAttachment(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseEventsCSV(bytes.NewReader(data))
+}
+
+// parseEventsCSV reads events from CSV. Every row is checked, and all problems
+// are returned together as an *importError rather than stopping at the first.
+func parseEventsCSV(r io.Reader) ([]db.Event, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	problems := &importError{}
+	var events []db.Event
+	var columns map[string]int
+	firstRow := true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				problems.add(parseErr.Line, "%v", parseErr.Err)
+				continue
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		// A header row names a "description" column, in any position
+		if firstRow {
+			firstRow = false
+			if isHeaderRecord(record) {
+				columns = parseHeader(record, line, problems)
+				continue
+			}
+		}
+
+		event, ok := parseEventRow(record, columns, line, problems)
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	if len(problems.lines) > 0 {
+		return nil, problems
+	}
+	return events, nil
+}
+
+// isHeaderRecord reports whether a first row is a header, which must name the description column
+func isHeaderRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.EqualFold(strings.TrimSpace(cell), columnDescription) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHeader maps column names to their positions
+func parseHeader(record []string, line int, problems *importError) map[string]int {
+	columns := make(map[string]int, len(record))
+	for i, cell := range record {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, column := range csvColumns {
+			known = known || column == name
+		}
+		if !known {
+			problems.add(line, "unknown column %q (expected %s)", cell, strings.Join(csvColumns, ", "))
+			continue
+		}
+		if _, dup := columns[name]; dup {
+			problems.add(line, "column %q appears more than once", name)
+			continue
+		}
+		columns[name] = i
+	}
+	return columns
+}
+
+// parseEventRow reads one data row, recording any problems against its line
+func parseEventRow(record []string, columns map[string]int, line int, problems *importError) (db.Event, bool) {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if columns == nil && name == columnDescription {
+			i, ok = 0, true
+		}
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	event := db.Event{
+		Description: cell(columnDescription),
+		Category:    cell(columnCategory),
+		Weight:      1,
+	}
+	ok := true
+
+	if err := checkEventDescription(event.Description); err != nil {
+		problems.add(line, "%v", err)
+		ok = false
+	}
+
+	if raw := cell(columnWeight); raw != "" {
+		weight, err := strconv.ParseFloat(raw, 64)
+		if err == nil {
+			err = checkEventWeight(weight)
+		}
+		if err != nil {
+			problems.add(line, "weight must be a number above 0 and at most %d, got %q", maxEventWeight, raw)
+			ok = false
+		} else {
+			event.Weight = weight
+		}
+	}
+
+	for _, tag := range strings.FieldsFunc(cell(columnTags), func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			event.Tags = append(event.Tags, tag)
+		}
+	}
+
+	if raw := cell(columnFree); raw != "" {
+		free, valid := parseCSVBool(raw)
+		if !valid {
+			problems.add(line, "free must be yes or no, got %q", raw)
+			ok = false
+		}
+		event.Free = free
+	}
+
+	return event, ok
+}
+
+// checkEventDescription validates an imported event's description
+func checkEventDescription(description string) error {
+	if description == "" {
+		return errors.New("missing description")
+	}
+	if utf8.RuneCountInString(description) > maxEventDescription {
+		return fmt.Errorf("description is longer than %d characters", maxEventDescription)
+	}
+	return nil
+}
+
+// checkEventWeight validates an imported event's weight
+func checkEventWeight(weight float64) error {
+	if weight <= 0 || weight > maxEventWeight {
+		return fmt.Errorf("must be above 0 and at most %d", maxEventWeight)
+	}
+	return nil
+}
+
+// parseCSVBool accepts the usual spreadsheet spellings of a boolean
+func parseCSVBool(raw string) (bool, bool) {
+	switch strings.ToLower(raw) {
+	case "1", "y", "yes", "true", "x":
+		return true, true
+	case "0", "n", "no", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// isBlankRecord reports whether every cell in a record is empty
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fordtom/bingo/db"
+)
+
+func TestParseEventsCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		csv       string
+		want      []db.Event
+		wantLines []string // importError lines, in order
+	}{
+		{
+			name: "no header",
+			csv:  "Someone sneezes\nDog barks, ignored\n",
+			want: []db.Event{
+				{Description: "Someone sneezes", Weight: 1},
+				{Description: "Dog barks", Weight: 1},
+			},
+		},
+		{
+			name: "header in any order",
+			csv:  "weight,Description,category\n2.5,Someone sneezes,noises\n,Dog barks,\n",
+			want: []db.Event{
+				{Description: "Someone sneezes", Category: "noises", Weight: 2.5},
+				{Description: "Dog barks", Weight: 1},
+			},
+		},
+		{
+			name: "blank rows skipped",
+			csv:  "\n,,\ndescription\n\nSomeone sneezes\n , \nDog barks\n",
+			want: []db.Event{
+				{Description: "Someone sneezes", Weight: 1},
+				{Description: "Dog barks", Weight: 1},
+			},
+		},
+		{
+			name: "tags split on commas and semicolons",
+			csv:  "description,tags\nSomeone sneezes,\"loud, rare;; indoor \"\n",
+			want: []db.Event{
+				{Description: "Someone sneezes", Weight: 1, Tags: []string{"loud", "rare", "indoor"}},
+			},
+		},
+		{
+			name: "free spellings",
+			csv:  "description,free\na,yes\nb,Y\nc,TRUE\nd,1\ne,x\nf,no\ng,0\nh,\n",
+			want: []db.Event{
+				{Description: "a", Weight: 1, Free: true},
+				{Description: "b", Weight: 1, Free: true},
+				{Description: "c", Weight: 1, Free: true},
+				{Description: "d", Weight: 1, Free: true},
+				{Description: "e", Weight: 1, Free: true},
+				{Description: "f", Weight: 1},
+				{Description: "g", Weight: 1},
+				{Description: "h", Weight: 1},
+			},
+		},
+		{
+			name: "weight bounds",
+			csv:  "description,weight\na,100\nb,0.01\nc,0\nd,-1\ne,100.5\nf,heavy\n",
+			wantLines: []string{
+				`line 4: weight must be a number above 0 and at most 100, got "0"`,
+				`line 5: weight must be a number above 0 and at most 100, got "-1"`,
+				`line 6: weight must be a number above 0 and at most 100, got "100.5"`,
+				`line 7: weight must be a number above 0 and at most 100, got "heavy"`,
+			},
+		},
+		{
+			name: "unknown and duplicate columns",
+			csv:  "description,colour,weight,Weight\nSomeone sneezes,red,1,2\n",
+			wantLines: []string{
+				`line 1: unknown column "colour" (expected description, category, weight, tags, free)`,
+				`line 1: column "weight" appears more than once`,
+			},
+		},
+		{
+			name: "every bad row reported at its line",
+			csv:  "description,free\n\nok,no\n,yes\nfine,maybe\n\n" + strings.Repeat("x", 201) + ",\n",
+			wantLines: []string{
+				"line 4: missing description",
+				`line 5: free must be yes or no, got "maybe"`,
+				"line 7: description is longer than 200 characters",
+			},
+		},
+		{
+			name: "long descriptions count characters",
+			csv:  strings.Repeat("é", 200) + "\n",
+			want: []db.Event{{Description: strings.Repeat("é", 200), Weight: 1}},
+		},
+		{
+			name:      "malformed quoting",
+			csv:       "description\nok\n\"unterminated\n",
+			wantLines: []string{`line 3: extraneous or missing " in quoted-field`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := parseEventsCSV(strings.NewReader(tt.csv))
+			if tt.wantLines != nil {
+				var problems *importError
+				if !errors.As(err, &problems) {
+					t.Fatalf("parseEventsCSV = %v, %v; want an *importError", events, err)
+				}
+				if !reflect.DeepEqual(problems.lines, tt.wantLines) {
+					t.Errorf("problems =\n%s\nwant\n%s", strings.Join(problems.lines, "\n"), strings.Join(tt.wantLines, "\n"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEventsCSV: %v", err)
+			}
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("parseEventsCSV =\n%+v\nwant\n%+v", events, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
@@ -13,19 +14,20 @@ func DeleteGame() *discordgo.ApplicationCommandOption {
 	return &discordgo.ApplicationCommandOption{
 		Type:        discordgo.ApplicationCommandOptionSubCommand,
 		Name:        "delete_game",
-		Description: "Delete a game and all associated data",
+		Description: "Delete a game after confirming; it can be restored until purged",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "game_id",
-				Description: "ID of the game to delete",
-				Required:    true,
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game to delete",
+				Required:     true,
+				Autocomplete: true,
 			},
 		},
 	}
 }
 
-// HandleDeleteGame processes the delete_game command
+// HandleDeleteGame asks the invoker to confirm deleting a game
 func HandleDeleteGame(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
 	ctx := context.Background()
 
@@ -35,30 +37,85 @@ func HandleDeleteGame(s *discordgo.Session, i *discordgo.InteractionCreate, opti
 		return
 	}
 
-	// Check if game exists and if it's active
-	game, err := database.GetGame(ctx, gameID)
+	// Check the game exists in this channel and the invoker may delete it
+	game, err := getChannelGame(ctx, database, i, gameID)
 	if err != nil {
-		respondError(s, i, "Error checking game: "+err.Error())
+		respondError(s, i, err.Error())
 		return
 	}
-	if game == nil {
-		respondError(s, i, fmt.Sprintf("Game #%d not found.", gameID))
+	if err := checkGameManager(ctx, database, i, game, "delete this game"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	players, err := database.GetPlayerCountForGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	open, closed, err := database.GetEventCounts(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("Delete game #%d (**%s**) with %d players and %d events (%d closed)?\n"+
+		"It will be hidden from this channel and can be brought back with `/%s restore_game` until it is purged.",
+		gameID, game.Title, players, open+closed, closed, Prefix)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Delete Game?",
+				Description: desc,
+				Color:       colorError,
+			}},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Delete",
+						Style:    discordgo.DangerButton,
+						CustomID: componentID(componentDeleteConfirm, gameID),
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: componentID(componentDeleteCancel, gameID),
+					},
+				}},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleDeleteConfirm soft-deletes a game once its deletion has been confirmed
+func handleDeleteConfirm(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64, database *db.DB) {
+	ctx := context.Background()
+
+	// Check again: the game may have changed since the prompt was shown
+	game, err := getChannelGame(ctx, database, i, gameID)
+	if err != nil {
+		updateError(s, i, err.Error())
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "delete this game"); err != nil {
+		updateError(s, i, err.Error())
 		return
 	}
 
 	wasActive := game.IsActive
 
-	// Delete the game and all associated data
-	if err := database.DeleteGameCascade(ctx, gameID); err != nil {
-		respondError(s, i, "Error deleting game: "+err.Error())
+	if err := database.SoftDeleteGame(ctx, gameID); err != nil {
+		updateError(s, i, "Error deleting game: "+err.Error())
 		return
 	}
 
-	response := fmt.Sprintf("✓ Game #%d (**%s**) deleted.", gameID, game.Title)
+	response := fmt.Sprintf("✓ Game #%d (**%s**) deleted by <@%s>. Restore it with `/%s restore_game`.", gameID, game.Title, i.Member.User.ID, Prefix)
 
 	// If we deleted the active game, set a new one
 	if wasActive {
-		games, err := database.ListGames(ctx)
+		games, err := database.ListGames(ctx, game.ChannelID)
 		if err == nil && len(games) > 0 {
 			// Set lowest ID game as active
 			minID := games[0].ID
@@ -75,6 +132,12 @@ func HandleDeleteGame(s *discordgo.Session, i *discordgo.InteractionCreate, opti
 		}
 	}
 
-	titleText := "Game Deleted"
-	respondEmbed(s, i, titleText, response, colorSuccess, false)
+	log.Printf("ok bg/delete_game actor=%s game_id=%d", i.Member.User.ID, gameID)
+	updateEmbed(s, i, "Game Deleted", fmt.Sprintf("✓ Game #%d deleted.", gameID), colorSuccess)
+	followupEmbed(s, i, "Game Deleted", response, colorSuccess, false)
+}
+
+// handleDeleteCancel dismisses a deletion prompt
+func handleDeleteCancel(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64) {
+	updateEmbed(s, i, "Deletion Cancelled", fmt.Sprintf("Game #%d was not deleted.", gameID), colorInfo)
 }
@@ -0,0 +1,311 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// maxEventDescription keeps event descriptions readable on boards and in menus
+const maxEventDescription = 200
+
+// Event returns the event subcommand group definition
+func Event() *discordgo.ApplicationCommandOption {
+	gameOption := &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionInteger,
+		Name:         "game_id",
+		Description:  "ID of the game (uses active game if not provided)",
+		Required:     false,
+		Autocomplete: true,
+	}
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:        "event",
+		Description: "Edit a game's events (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "edit",
+				Description: "Fix an event's description",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionInteger,
+						Name:         "event_id",
+						Description:  "ID of the event to edit",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "description",
+						Description: "The new description",
+						Required:    true,
+						MaxLength:   maxEventDescription,
+					},
+					gameOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add an event mid-game; it can then replace retired events on boards",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "description",
+						Description: "What has to happen",
+						Required:    true,
+						MaxLength:   maxEventDescription,
+					},
+					gameOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "retire",
+				Description: "Withdraw an event, replacing or freeing its squares on every board",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionInteger,
+						Name:         "event_id",
+						Description:  "ID of the event to retire",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mode",
+						Description: "What its squares become (default replace)",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "replace — swap in an open event not already on the board", Value: string(db.RetireReplace)},
+							{Name: "free — a free square that is always marked", Value: string(db.RetireFree)},
+						},
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionInteger,
+						Name:         "replacement",
+						Description:  "Open event to swap in where possible (default: the least used)",
+						Required:     false,
+						Autocomplete: true,
+					},
+					gameOption,
+				},
+			},
+		},
+	}
+}
+
+// HandleEvent routes the event subcommand group
+func HandleEvent(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	if len(options) == 0 {
+		return
+	}
+	subCmd := options[0]
+
+	switch subCmd.Name {
+	case "edit":
+		handleEventEdit(s, i, subCmd.Options, database)
+	case "add":
+		handleEventAdd(s, i, subCmd.Options, database)
+	case "retire":
+		handleEventRetire(s, i, subCmd.Options, database)
+	}
+}
+
+// editableGame resolves the selected or active game and checks the invoker may edit its events
+func editableGame(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) (*db.Game, error) {
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		return nil, err
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching game: %w", err)
+	}
+	if err := checkGameManager(ctx, database, i, game, "edit events"); err != nil {
+		return nil, err
+	}
+	if err := checkEditable(game, "edit its events"); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// lookupEvent fetches an event by display ID, rejecting retired events
+func lookupEvent(ctx context.Context, database *db.DB, gameID int64, displayID int64) (*db.Event, error) {
+	event, err := database.GetEventByDisplayID(ctx, gameID, int(displayID))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching event: %w", err)
+	}
+	if event == nil {
+		return nil, fmt.Errorf("event #%d not found in game #%d", displayID, gameID)
+	}
+	if event.Status == string(db.EventStatusRetired) {
+		return nil, fmt.Errorf("event #%d has already been retired", displayID)
+	}
+	return event, nil
+}
+
+// handleEventEdit processes event edit
+func handleEventEdit(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	displayID, ok := getIntOption(options, "event_id")
+	if !ok {
+		respondError(s, i, "Missing required event_id option.")
+		return
+	}
+	description, _ := getStringOption(options, "description")
+	description = strings.TrimSpace(description)
+	if description == "" {
+		respondError(s, i, "The description can't be empty.")
+		return
+	}
+
+	game, err := editableGame(ctx, database, i, options)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	event, err := lookupEvent(ctx, database, game.ID, displayID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	if err := database.UpdateEventDescription(ctx, event.ID, description); err != nil {
+		respondError(s, i, "Error updating event: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Event #%d in game #%d now reads **%s**\n(was: %s)", displayID, game.ID, description, event.Description)
+	respondEmbed(s, i, "Event Updated", desc, colorSuccess, false)
+	refreshEventPanels(s, database, game.ID, 0)
+	refreshLiveBoards(s, database, game.ID)
+}
+
+// handleEventAdd processes event add
+func handleEventAdd(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	description, _ := getStringOption(options, "description")
+	description = strings.TrimSpace(description)
+	if description == "" {
+		respondError(s, i, "The description can't be empty.")
+		return
+	}
+
+	game, err := editableGame(ctx, database, i, options)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	event, err := database.AddEvent(ctx, game.ID, description)
+	if err != nil {
+		respondError(s, i, "Error adding event: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Added event #%d to game #%d: **%s**\nIt can be voted on now, and will replace retired events on boards.", event.DisplayID, game.ID, description)
+	respondEmbed(s, i, "Event Added", desc, colorSuccess, false)
+	refreshEventPanels(s, database, game.ID, 0)
+}
+
+// handleEventRetire processes event retire
+func handleEventRetire(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	displayID, ok := getIntOption(options, "event_id")
+	if !ok {
+		respondError(s, i, "Missing required event_id option.")
+		return
+	}
+	mode := db.RetireReplace
+	if m, ok := getStringOption(options, "mode"); ok {
+		mode = db.RetireMode(m)
+	}
+
+	game, err := editableGame(ctx, database, i, options)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	event, err := lookupEvent(ctx, database, game.ID, displayID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	var replacementID int64
+	if replacementDisplayID, ok := getIntOption(options, "replacement"); ok {
+		if mode != db.RetireReplace {
+			respondError(s, i, "A replacement can only be used with the replace mode.")
+			return
+		}
+		replacement, err := lookupEvent(ctx, database, game.ID, replacementDisplayID)
+		if err != nil {
+			respondError(s, i, err.Error())
+			return
+		}
+		if replacement.ID == event.ID {
+			respondError(s, i, "An event can't replace itself.")
+			return
+		}
+		if replacement.Status != string(db.EventStatusOpen) {
+			respondError(s, i, fmt.Sprintf("Event #%d has already closed, so it can't replace an open square. Choose an open event.", replacementDisplayID))
+			return
+		}
+		replacementID = replacement.ID
+	}
+
+	result, err := database.RetireEvent(ctx, game.ID, event.ID, mode, replacementID)
+	if errors.Is(err, db.ErrEventNotFound) {
+		respondError(s, i, fmt.Sprintf("Event #%d not found in game #%d.", displayID, game.ID))
+		return
+	}
+	if errors.Is(err, db.ErrEventRetired) {
+		respondError(s, i, fmt.Sprintf("Event #%d has already been retired.", displayID))
+		return
+	}
+	if errors.Is(err, db.ErrFreeEvent) {
+		respondError(s, i, fmt.Sprintf("Event #%d is a free square and is always marked, so it can't be retired.", displayID))
+		return
+	}
+	if err != nil {
+		respondError(s, i, "Error retiring event: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Retired event #%d: ~~%s~~", displayID, event.Description)
+	switch {
+	case result.Replaced == 0 && result.Freed == 0:
+		desc += "\nIt wasn't on any boards."
+	default:
+		if result.Replaced > 0 {
+			desc += fmt.Sprintf("\n%d squares got a replacement event.", result.Replaced)
+		}
+		if result.Freed > 0 {
+			desc += fmt.Sprintf("\n%d squares are now free.", result.Freed)
+		}
+	}
+
+	stages, _ := rules.ParseStages(game.WinPattern)
+	if len(result.Revoked) > 0 {
+		desc += "\n\n**Revoked wins:**\n" + formatWins(result.Revoked, stages)
+	}
+	color := colorSuccess
+	if len(result.Wins) > 0 {
+		desc += "\n\n🏆 **BINGO!**\n" + formatWins(result.Wins, stages)
+		color = colorWin
+	}
+	respondEmbed(s, i, "Event Retired", desc, color, false)
+	refreshEventPanels(s, database, game.ID, 0)
+	refreshLiveBoards(s, database, game.ID)
+}
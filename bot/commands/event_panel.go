@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+const (
+	panelPageSize    = 25 // Discord's limit on select menu options
+	panelDescription = 90 // truncate event descriptions in the panel above this
+)
+
+// EventPanel returns the event_panel subcommand definition
+func EventPanel() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "event_panel",
+		Description: "Post an interactive panel for voting on open events",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleEventPanel processes the event_panel command
+func HandleEventPanel(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	embed, components, err := buildEventPanel(ctx, database, gameID, 0)
+	if err != nil {
+		respondError(s, i, "Error building event panel: "+err.Error())
+		return
+	}
+
+	log.Printf("ok %s actor=%s game_id=%d", interactionLabel(i), interactionActor(i), gameID)
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}); err != nil {
+		log.Printf("err %s actor=%s panel post failed: %v", interactionLabel(i), interactionActor(i), err)
+		return
+	}
+
+	// Track the panel so votes cast elsewhere keep it current
+	msg, err := s.InteractionResponse(i.Interaction)
+	if err == nil {
+		err = database.TrackPanel(ctx, db.EventPanel{
+			MessageID: parseSnowflake(msg.ID),
+			ChannelID: parseSnowflake(msg.ChannelID),
+			GameID:    gameID,
+		})
+	}
+	if err != nil {
+		followupEmbed(s, i, "Error", "Panel posted, but it will only update when clicked: "+err.Error(), colorError, true)
+	}
+}
+
+// handlePanelVote records a vote chosen from the panel's select menu and refreshes the panel
+func handlePanelVote(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64, page int, database *db.DB) {
+	ctx := context.Background()
+	userID := parseSnowflake(i.Member.User.ID)
+
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+	displayID, err := strconv.Atoi(values[0])
+	if err != nil {
+		respondError(s, i, "Invalid event selection.")
+		return
+	}
+
+	result, voteErr := castVote(ctx, database, gameID, displayID, userID)
+
+	// Refresh the panel whatever the outcome so stale counts and closed events disappear
+	if !updateEventPanel(s, i, database, gameID, page) {
+		return
+	}
+
+	if voteErr != nil {
+		log.Printf("err %s actor=%s msg=%q", interactionLabel(i), interactionActor(i), voteErr.Error())
+		followupEmbed(s, i, "Error", voteErr.Error(), colorError, true)
+		return
+	}
+
+	log.Printf("ok bg/vote actor=%s game_id=%d event_display_id=%d closed=%t source=panel", i.Member.User.ID, gameID, displayID, result.Closed)
+	title, desc, color := formatVote(result)
+	// Closing an event is news for everyone; a plain vote is only confirmed to the voter
+	boards, files := winnerBoards(ctx, s, i, database, gameID, result.Wins, result.Stages)
+	followupEmbedWithBoards(s, i, title, desc, color, !result.Closed, boards, files)
+
+	refreshEventPanels(s, database, gameID, parseSnowflake(i.Message.ID))
+	if result.Closed {
+		refreshLiveBoards(s, database, gameID)
+	}
+}
+
+// handlePanelPage switches the panel to another page, also used to refresh the current one
+func handlePanelPage(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64, page int, database *db.DB) {
+	updateEventPanel(s, i, database, gameID, page)
+}
+
+// updateEventPanel re-renders the clicked panel in place, reporting failures to the user
+func updateEventPanel(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB, gameID int64, page int) bool {
+	ctx := context.Background()
+	embed, components, err := buildEventPanel(ctx, database, gameID, page)
+	if err != nil {
+		respondError(s, i, "Error refreshing event panel: "+err.Error())
+		return false
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}); err != nil {
+		log.Printf("err %s actor=%s panel update failed: %v", interactionLabel(i), interactionActor(i), err)
+		return false
+	}
+	if err := database.SetPanelPage(ctx, parseSnowflake(i.Message.ID), page); err != nil {
+		log.Printf("err %s actor=%s panel page not saved: %v", interactionLabel(i), interactionActor(i), err)
+	}
+	return true
+}
+
+// refreshEventPanels re-renders every tracked panel in a game, except the one
+// with message ID skip (0 for none), which an interaction has just updated. Call
+// it after votes are cast or withdrawn and after events close, reopen or change.
+// Panels that have since been deleted stop being tracked.
+func refreshEventPanels(s *discordgo.Session, database *db.DB, gameID, skip int64) {
+	ctx := context.Background()
+	panels, err := database.GetEventPanels(ctx, gameID)
+	if err != nil {
+		log.Printf("Error fetching event panels for game %d: %v", gameID, err)
+		return
+	}
+
+	for _, panel := range panels {
+		if panel.MessageID == skip {
+			continue
+		}
+		embed, components, err := buildEventPanel(ctx, database, gameID, panel.Page)
+		if err == nil {
+			_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+				ID:         fmt.Sprint(panel.MessageID),
+				Channel:    fmt.Sprint(panel.ChannelID),
+				Embeds:     &[]*discordgo.MessageEmbed{embed},
+				Components: &components,
+			})
+		}
+		if messageGone(err) {
+			err = database.UntrackPanel(ctx, panel.MessageID)
+		}
+		if err != nil {
+			log.Printf("Error refreshing event panel %d in game %d: %v", panel.MessageID, gameID, err)
+		}
+	}
+}
+
+// buildEventPanel renders one page of open events as an embed with a vote menu and paging buttons
+func buildEventPanel(ctx context.Context, database *db.DB, gameID int64, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if game == nil || game.DeletedAt != nil {
+		return nil, nil, fmt.Errorf("game #%d no longer exists", gameID)
+	}
+
+	events, err := database.GetGameEvents(ctx, gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consensus, err := rules.ParseConsensus(game.Consensus)
+	if err != nil {
+		return nil, nil, err
+	}
+	eligibility, err := rules.ParseEligibility(game.Eligibility)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var open []db.Event
+	closed := 0
+	for _, event := range events {
+		switch db.EventStatus(event.Status) {
+		case db.EventStatusOpen:
+			open = append(open, event)
+		case db.EventStatusClosed:
+			closed++
+		}
+	}
+
+	pages := (len(open) + panelPageSize - 1) / panelPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	page = max(0, min(page, pages-1))
+
+	embed := &discordgo.MessageEmbed{
+		Title:     fmt.Sprintf("Event Panel — Game #%d: %s", game.ID, game.Title),
+		Color:     colorInfo,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d · %d open · %d closed", page+1, pages, len(open), closed),
+		},
+	}
+
+	if len(open) == 0 {
+		embed.Description = "All events have closed. 🎉"
+		// An empty (not nil) slice clears the menu when the panel is updated
+		return embed, []discordgo.MessageComponent{}, nil
+	}
+
+	pageEvents := open[page*panelPageSize : min((page+1)*panelPageSize, len(open))]
+	lines := make([]string, 0, len(pageEvents))
+	menuOptions := make([]discordgo.SelectMenuOption, 0, len(pageEvents))
+	for _, event := range pageEvents {
+		tally, err := database.GetEventTally(ctx, game, event.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		progress := voteProgress(consensus, eligibility, tally)
+		desc := truncate(event.Description, panelDescription)
+		lines = append(lines, fmt.Sprintf("**#%d** %s (%s)", event.DisplayID, desc, progress))
+		menuOptions = append(menuOptions, discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("#%d %s", event.DisplayID, event.Description), 100),
+			Value:       strconv.Itoa(event.DisplayID),
+			Description: progress,
+		})
+	}
+	intro := "Pick an event below once it has happened."
+	if err := checkAcceptsVotes(game); err != nil {
+		intro = describeState(db.GameState(game.State)) + ": votes aren't being accepted right now."
+	}
+	embed.Description = fmt.Sprintf("%s\nConsensus: %s\n\n", intro, consensus.Describe()) + strings.Join(lines, "\n")
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    componentID(componentPanelVote, gameID, page),
+				Placeholder: "Vote that an event occurred…",
+				Options:     menuOptions,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "◀ Prev",
+				Style:    discordgo.SecondaryButton,
+				CustomID: componentID(componentPanelPage, gameID, page-1),
+				Disabled: page == 0,
+			},
+			discordgo.Button{
+				Label:    "Refresh",
+				Style:    discordgo.PrimaryButton,
+				CustomID: componentID(componentPanelPage, gameID, page),
+			},
+			discordgo.Button{
+				Label:    "Next ▶",
+				Style:    discordgo.SecondaryButton,
+				CustomID: componentID(componentPanelPage, gameID, page+1),
+				Disabled: page >= pages-1,
+			},
+		}},
+	}
+	return embed, components, nil
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
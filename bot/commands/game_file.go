@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// gameFileVersion is the game file schema version this bot reads
+const gameFileVersion = 1
+
+// gameDefinition is a game described by a JSON or YAML game file. Fields the
+// file leaves out keep their zero value so new_game options can fill them in.
+type gameDefinition struct {
+	Title         string
+	GridSize      int
+	Players       []int64
+	Referees      []int64
+	Events        []db.Event
+	WinPattern    string
+	Places        int
+	Consensus     string
+	Voting        string
+	Draft         bool
+	FreeCell      string
+	Dealing       string
+	Lobby         bool
+	PrivateBoards bool
+	DMBoards      bool
+}
+
+// gameFileFields lists the top-level keys of a game file
+var gameFileFields = []string{
+	"schema_version", "title", "grid_size", "players", "referees", "events",
+	"win_pattern", "places", "consensus", "voting", "draft", "free_cell",
+	"dealing", "lobby", "private_boards", "dm_boards",
+}
+
+// eventFields lists the keys of an event object in a game file
+var eventFields = []string{columnDescription, columnCategory, columnWeight, columnTags, columnFree}
+
+// snowflakeRegex matches a user mention or a bare user ID
+var snowflakeRegex = regexp.MustCompile(`^(?:<@!?(\d+)>|(\d+))$`)
+
+// fetchGameFile fetches and parses a game file
+func fetchGameFile(url string) (*gameDefinition, error) {
+	data, err := fetchAttachment(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseGameFile(data)
+}
+
+// parseGameFile reads a game definition from JSON or YAML (JSON is valid YAML).
+// Every field is checked, and all problems are returned together as an
+// *importError naming the path of each offending value, e.g. events[3].weight.
+func parseGameFile(data []byte) (*gameDefinition, error) {
+	var root any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("not valid JSON or YAML: %v", err)
+	}
+	fields, ok := root.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("a game file must be an object with a schema_version")
+	}
+
+	problems := &importError{}
+	def := &gameDefinition{}
+	checkKeys(fields, gameFileFields, "", problems)
+
+	version, ok := fields["schema_version"]
+	if !ok {
+		problems.addPath("schema_version", "required; this bot reads version %d", gameFileVersion)
+	} else if n, ok := intValue(version); !ok || n != gameFileVersion {
+		problems.addPath("schema_version", "unsupported version %v; this bot reads version %d", version, gameFileVersion)
+	}
+
+	if v, ok := fields["title"]; ok {
+		def.Title = stringField(v, "title", problems)
+	}
+	if v, ok := fields["grid_size"]; ok {
+		def.GridSize = intField(v, "grid_size", 2, 10, problems)
+	}
+	if v, ok := fields["players"]; ok {
+		def.Players = userListField(v, "players", problems)
+	}
+	if v, ok := fields["referees"]; ok {
+		def.Referees = userListField(v, "referees", problems)
+	}
+	if v, ok := fields["events"]; ok {
+		def.Events = eventsField(v, "events", problems)
+	}
+
+	if v, ok := fields["win_pattern"]; ok {
+		// A list is read as stages, one pattern each
+		if list, isList := v.([]any); isList {
+			stages := make([]string, len(list))
+			for i, item := range list {
+				stages[i] = stringField(item, fmt.Sprintf("win_pattern[%d]", i), problems)
+			}
+			def.WinPattern = strings.Join(stages, " > ")
+		} else {
+			def.WinPattern = stringField(v, "win_pattern", problems)
+		}
+		stages, err := rules.ParseStages(def.WinPattern)
+		if err != nil {
+			problems.addPath("win_pattern", "%v", err)
+		}
+		for _, stage := range stages {
+			if err := stage.Validate(def.GridSize); def.GridSize > 0 && err != nil {
+				problems.addPath("win_pattern", "%v", err)
+			}
+		}
+	}
+	if v, ok := fields["places"]; ok {
+		def.Places = intField(v, "places", 1, 10, problems)
+	}
+	if v, ok := fields["consensus"]; ok {
+		def.Consensus = stringField(v, "consensus", problems)
+		if _, err := rules.ParseConsensus(def.Consensus); err != nil {
+			problems.addPath("consensus", "%v", err)
+		}
+	}
+	if v, ok := fields["voting"]; ok {
+		def.Voting = stringField(v, "voting", problems)
+		if _, err := rules.ParseEligibility(def.Voting); err != nil {
+			problems.addPath("voting", "%v", err)
+		}
+	}
+	if v, ok := fields["free_cell"]; ok {
+		def.FreeCell = stringField(v, "free_cell", problems)
+		if _, err := rules.ParseFreeCell(def.FreeCell, def.GridSize); def.GridSize > 0 && err != nil {
+			problems.addPath("free_cell", "%v", err)
+		}
+	}
+	if v, ok := fields["dealing"]; ok {
+		def.Dealing = stringField(v, "dealing", problems)
+		if !slices.Contains(db.DealModes, db.DealMode(def.Dealing)) {
+			problems.addPath("dealing", "must be standard or fair")
+		}
+	}
+	if v, ok := fields["draft"]; ok {
+		draft, isBool := v.(bool)
+		if !isBool {
+			problems.addPath("draft", "must be true or false")
+		}
+		def.Draft = draft
+	}
+	if v, ok := fields["lobby"]; ok {
+		lobby, isBool := v.(bool)
+		if !isBool {
+			problems.addPath("lobby", "must be true or false")
+		}
+		def.Lobby = lobby
+	}
+	if v, ok := fields["private_boards"]; ok {
+		private, isBool := v.(bool)
+		if !isBool {
+			problems.addPath("private_boards", "must be true or false")
+		}
+		def.PrivateBoards = private
+	}
+	if v, ok := fields["dm_boards"]; ok {
+		dm, isBool := v.(bool)
+		if !isBool {
+			problems.addPath("dm_boards", "must be true or false")
+		}
+		def.DMBoards = dm
+	}
+
+	if len(problems.lines) > 0 {
+		return nil, problems
+	}
+	return def, nil
+}
+
+// eventsField reads the events list. Each item is a description string or an
+// object with the same fields as the CSV columns.
+func eventsField(v any, path string, problems *importError) []db.Event {
+	list, ok := v.([]any)
+	if !ok {
+		problems.addPath(path, "must be a list of events")
+		return nil
+	}
+
+	events := make([]db.Event, 0, len(list))
+	for i, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		event := db.Event{Weight: 1}
+
+		switch item := item.(type) {
+		case string:
+			event.Description = strings.TrimSpace(item)
+		case map[string]any:
+			checkKeys(item, eventFields, itemPath+".", problems)
+			if v, ok := item[columnDescription]; ok {
+				event.Description = strings.TrimSpace(stringField(v, itemPath+".description", problems))
+			}
+			if v, ok := item[columnCategory]; ok {
+				event.Category = strings.TrimSpace(stringField(v, itemPath+".category", problems))
+			}
+			if v, ok := item[columnWeight]; ok {
+				weight, isNumber := floatValue(v)
+				if !isNumber {
+					problems.addPath(itemPath+".weight", "must be a number")
+				} else if err := checkEventWeight(weight); err != nil {
+					problems.addPath(itemPath+".weight", "%v", err)
+				} else {
+					event.Weight = weight
+				}
+			}
+			if v, ok := item[columnTags]; ok {
+				event.Tags = tagsField(v, itemPath+".tags", problems)
+			}
+			if v, ok := item[columnFree]; ok {
+				free, isBool := v.(bool)
+				if !isBool {
+					problems.addPath(itemPath+".free", "must be true or false")
+				}
+				event.Free = free
+			}
+		default:
+			problems.addPath(itemPath, "must be a description or an object with a description")
+			continue
+		}
+
+		if err := checkEventDescription(event.Description); err != nil {
+			problems.addPath(itemPath+".description", "%v", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// tagsField reads tags given as a list of strings or a comma-separated string
+func tagsField(v any, path string, problems *importError) []string {
+	var raw []string
+	switch v := v.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []any:
+		for i, item := range v {
+			raw = append(raw, stringField(item, fmt.Sprintf("%s[%d]", path, i), problems))
+		}
+	default:
+		problems.addPath(path, "must be a list of strings")
+	}
+
+	var tags []string
+	for _, tag := range raw {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// userListField reads a list of user mentions or IDs. IDs are best quoted, as
+// many JSON tools cannot hold a Discord ID as a number exactly.
+func userListField(v any, path string, problems *importError) []int64 {
+	list, ok := v.([]any)
+	if !ok {
+		problems.addPath(path, "must be a list of user mentions or IDs")
+		return nil
+	}
+
+	ids := make([]int64, 0, len(list))
+	for i, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		var raw string
+		switch item := item.(type) {
+		case string:
+			raw = strings.TrimSpace(item)
+		case int:
+			raw = strconv.Itoa(item)
+		default:
+			problems.addPath(itemPath, "must be a user mention like <@123> or an ID in quotes")
+			continue
+		}
+		match := snowflakeRegex.FindStringSubmatch(raw)
+		if match == nil {
+			problems.addPath(itemPath, "%q is not a user mention or ID", raw)
+			continue
+		}
+		id, err := strconv.ParseInt(match[1]+match[2], 10, 64)
+		if err != nil {
+			problems.addPath(itemPath, "%q is not a valid user ID", raw)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// stringField reads a string value
+func stringField(v any, path string, problems *importError) string {
+	s, ok := v.(string)
+	if !ok {
+		problems.addPath(path, "must be a string")
+	}
+	return s
+}
+
+// intField reads a whole number between min and max
+func intField(v any, path string, min, max int, problems *importError) int {
+	n, ok := intValue(v)
+	if !ok || n < min || n > max {
+		problems.addPath(path, "must be a whole number from %d to %d", min, max)
+		return 0
+	}
+	return n
+}
+
+// intValue converts a decoded YAML number to an int
+func intValue(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt32 {
+			return int(v), true
+		}
+	}
+	return 0, false
+}
+
+// floatValue converts a decoded YAML number to a float64
+func floatValue(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// checkKeys reports keys of an object that the schema does not define
+func checkKeys(fields map[string]any, known []string, prefix string, problems *importError) {
+	var unknown []string
+	for key := range fields {
+		found := false
+		for _, k := range known {
+			found = found || k == key
+		}
+		if !found {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems.addPath(prefix+key, "unknown field (expected one of %s)", strings.Join(known, ", "))
+	}
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fordtom/bingo/db"
+)
+
+func TestParseGameFile(t *testing.T) {
+	valid := &gameDefinition{
+		Title:      "Office party",
+		GridSize:   3,
+		Players:    []int64{123, 456},
+		WinPattern: "line > blackout",
+		Consensus:  "fixed:2",
+		Events: []db.Event{
+			{Description: "Someone sneezes", Weight: 1},
+			{Description: "Cake", Category: "food", Weight: 2, Tags: []string{"sweet", "late"}, Free: true},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		want      *gameDefinition
+		wantPaths []string // the path each problem names, in order
+	}{
+		{
+			name: "json",
+			file: `{"schema_version": 1, "title": "Office party", "grid_size": 3,
+				"players": ["<@123>", "456"], "win_pattern": ["line", "blackout"], "consensus": "fixed:2",
+				"events": ["Someone sneezes", {"description": "Cake", "category": "food", "weight": 2, "tags": ["sweet", "late"], "free": true}]}`,
+			want: valid,
+		},
+		{
+			name: "yaml",
+			file: `schema_version: 1
+title: Office party
+grid_size: 3
+players: ["<@!123>", 456]
+win_pattern: line > blackout
+consensus: fixed:2
+events:
+  - Someone sneezes
+  - description: Cake
+    category: food
+    weight: 2
+    tags: sweet, late
+    free: true
+`,
+			want: valid,
+		},
+		{
+			name:      "missing schema version",
+			file:      `{"title": "x"}`,
+			wantPaths: []string{"schema_version"},
+		},
+		{
+			name:      "unsupported schema version",
+			file:      "schema_version: 2\ntitle: x\n",
+			wantPaths: []string{"schema_version"},
+		},
+		{
+			name: "bad nested event fields",
+			file: `{"schema_version": 1, "events": ["ok",
+				{"description": "heavy", "weight": 500},
+				{"description": "tagged", "tags": [1]},
+				{"weight": 2},
+				{"description": "x", "free": "yes", "colour": "red"},
+				7]}`,
+			wantPaths: []string{
+				"events[1].weight",
+				"events[2].tags[0]",
+				"events[3].description",
+				"events[4].colour",
+				"events[4].free",
+				"events[5]",
+			},
+		},
+		{
+			name:      "bad pattern stage",
+			file:      "schema_version: 1\nwin_pattern: [line, diagonal]\n",
+			wantPaths: []string{"win_pattern"},
+		},
+		{
+			name:      "pattern stage that isn't a string",
+			file:      "schema_version: 1\nwin_pattern: [line, 42]\n",
+			wantPaths: []string{"win_pattern[1]"},
+		},
+		{
+			name:      "pattern that doesn't fit the grid",
+			file:      "schema_version: 1\ngrid_size: 4\nwin_pattern: plus\n",
+			wantPaths: []string{"win_pattern"},
+		},
+		{
+			name:      "bad consensus and voting",
+			file:      `{"schema_version": 1, "consensus": "percent:150", "voting": "everyone"}`,
+			wantPaths: []string{"consensus", "voting"},
+		},
+		{
+			name:      "bad players and unknown top-level field",
+			file:      `{"schema_version": 1, "players": ["@bob", 1.5], "grid": 5}`,
+			wantPaths: []string{"grid", "players[0]", "players[1]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := parseGameFile([]byte(tt.file))
+			if tt.wantPaths != nil {
+				var problems *importError
+				if !errors.As(err, &problems) {
+					t.Fatalf("parseGameFile = %+v, %v; want an *importError", def, err)
+				}
+				paths := make([]string, len(problems.lines))
+				for n, line := range problems.lines {
+					paths[n], _, _ = strings.Cut(line, ": ")
+				}
+				if !reflect.DeepEqual(paths, tt.wantPaths) {
+					t.Errorf("problem paths = %q, want %q\n%s", paths, tt.wantPaths, strings.Join(problems.lines, "\n"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGameFile: %v", err)
+			}
+			if !reflect.DeepEqual(def, tt.want) {
+				t.Errorf("parseGameFile =\n%+v\nwant\n%+v", def, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGameFileNotAnObject(t *testing.T) {
+	for _, file := range []string{"[1, 2]", "{unclosed", "just text"} {
+		if _, err := parseGameFile([]byte(file)); err == nil {
+			t.Errorf("parseGameFile(%q) succeeded, want an error", file)
+		}
+	}
+}
@@ -1,8 +1,20 @@
 package commands
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// Help topics; each fits in a single embed
+const (
+	helpCommands = "commands"
+	helpSetup    = "setup"
+	helpRules    = "rules"
 )
 
 // Help returns the help subcommand definition
@@ -11,29 +23,116 @@ func Help() *discordgo.ApplicationCommandOption {
 		Type:        discordgo.ApplicationCommandOptionSubCommand,
 		Name:        "help",
 		Description: "Display help information about all bot commands",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "topic",
+				Description: "What to explain (default commands)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "commands — every /bingo command", Value: helpCommands},
+					{Name: "setup — CSV columns, game files and event packs", Value: helpSetup},
+					{Name: "rules — win patterns, game states and voting", Value: helpRules},
+				},
+			},
+		},
 	}
 }
 
 // HandleHelp processes the help command
 func HandleHelp(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
 	prefix := "/" + Prefix
-	helpText := "**Game Management**\n" +
-		"• `" + prefix + " new_game` - Create a game with events and player boards (requires CSV)\n" +
-		"• `" + prefix + " delete_game <game_id>` - Delete a game and all data\n" +
-		"• `" + prefix + " set_active_game <game_id>` - Set the active game\n\n" +
-		"**Game Information**\n" +
-		"• `" + prefix + " list_games` - List all games with stats\n" +
-		"• `" + prefix + " list_events [game_id]` - List events with vote counts\n" +
-		"• `" + prefix + " view_board <user> [game_id]` - View a player's board\n\n" +
-		"**Gameplay**\n" +
-		"• `" + prefix + " vote <event_id> [game_id]` - Vote that an event occurred\n" +
-		"• `" + prefix + " help` - Show this help\n\n" +
-		"**CSV Format**\n" +
-		"One event per line, optional header row:\n" +
-		"```\ndescription\nFirst event\nSecond event\n```\n\n" +
-		"**Voting**\n" +
-		"• Consensus: 100% for ≤3 players, 60% for larger games\n" +
-		"• When consensus reached, event closes and winners are checked"
-
-	respondEmbed(s, i, "BingoBot Commands", helpText, colorInfo, false)
+	more := "\n\nMore: `" + prefix + " help topic:setup` and `" + prefix + " help topic:rules`"
+
+	topic, _ := getStringOption(options, "topic")
+	switch topic {
+	case helpSetup:
+		helpText := "**CSV Format**\n" +
+			"One event per line. A header row unlocks more columns, in any order:\n" +
+			"```\ndescription,category,weight,tags,free\nFirst event,food,2,,\nFree space,,,,yes\n```\n" +
+			"• `weight` puts an event on proportionally more boards (default 1)\n" +
+			"• Each board mixes its `category` values; `free` squares start marked\n" +
+			"• Problems are reported per line, all at once\n\n" +
+			"**Free Square** (`free_cell` on new_game)\n" +
+			"`center` (odd grids) or `row,col` such as `2,3` puts a pre-marked ★ FREE square in that spot on every board\n\n" +
+			"**Lobby** (`lobby` on new_game)\n" +
+			"Posts Join/Leave buttons so players sign themselves up; `player_ids` is optional. The host presses Start to deal boards to the roster and open voting. Late arrivals after that use `add_player`\n\n" +
+			"**Secret Boards** (`private_boards` and `dm_boards` on new_game)\n" +
+			"`private_boards` hides other players' boards from `view_board` until the game finishes; everyone sees their own with `my_board`. `dm_boards` sends every player their board by DM when voting opens\n\n" +
+			"**Dealing** (`dealing` on new_game)\n" +
+			"`fair` uses every event about as often as its weight deserves, keeps boards from sharing too many squares and balances each board's total weight and categories. new_game reports overlap, event usage and difficulty spread\n\n" +
+			"**Game Files** (`game_file` on new_game)\n" +
+			"JSON or YAML with `schema_version: 1` plus new_game's settings (`players` and `events` as lists; events may use the CSV columns as keys). Options you pass override the file\n\n" +
+			"**Event Packs**\n" +
+			"• `" + prefix + " pack save <name> <events_csv>` - Save a CSV for reuse in this server\n" +
+			"• `" + prefix + " pack snapshot <name> [game_id]` - Save a game's current events\n" +
+			"• `" + prefix + " pack list`, `pack show <name>` - Browse saved packs\n" +
+			"• `" + prefix + " pack delete <name>` - Delete a pack (its creator or an admin)\n" +
+			"• `" + prefix + " new_game pack:<name>` - Use a pack instead of a CSV"
+		respondEmbed(s, i, "BingoBot Setup", helpText, colorInfo, false)
+	case helpRules:
+		helpText := "**Win Patterns** (`win_pattern` on new_game)\n" +
+			"• `line` (default) - any row, column or diagonal\n" +
+			"• `lines:N` - N different lines\n" +
+			"• `blackout`, `corners`, `x`, `plus`\n" +
+			"• Custom mask, rows split by `/`, X = required: `X...X/.X.X./..X../.X.X./X...X`\n" +
+			"• Stages with `>`: `line > lines:2 > blackout` moves on once a stage's places are won\n" +
+			"• `places` sets how many finishers each stage rewards (default 3)\n\n" +
+			"**Game States**\n" +
+			"• `new_game draft:True` prepares a game without opening voting\n" +
+			"• draft → running ⇄ paused → finished → archived; only running games take votes\n" +
+			"• Finishing freezes the boards and records the result; archiving hides the game\n\n" +
+			"**Voting** (`consensus` on new_game)\n" +
+			consensusHelp(ctx, database, i) +
+			"• When consensus reached, event closes and winners are checked; the announcement shows each winner's board with the winning lines struck through\n" +
+			"• `voting` on new_game decides whose votes count: `players` (default), `referees` (players plus the `referees` you name) or `open:N` (anyone; N spectator votes also close an event). The host can always vote"
+		respondEmbed(s, i, "BingoBot Rules", helpText, colorInfo, false)
+	default:
+		helpText := "**Game Management**\n" +
+			"• `" + prefix + " new_game` - Create a game with events and player boards (from a CSV, game file or pack)\n" +
+			"• `" + prefix + " delete_game <game_id>` - Delete a game after confirming (host or admin)\n" +
+			"• `" + prefix + " restore_game <game_id>` - Restore a deleted game before it is purged (host or admin)\n" +
+			"• `" + prefix + " set_active_game <game_id>` - Set the active game for this channel (host or admin)\n" +
+			"• `" + prefix + " set_game_state <state> [game_id]` - Start, pause, resume, finish or archive a game (host or admin)\n" +
+			"• `" + prefix + " set_consensus <consensus> [game_id]` - Change the vote rule (host or admin)\n" +
+			"• `" + prefix + " set_admin_role [role]` - Let a role manage every game; once set, only admins create games (Manage Server)\n" +
+			"• `" + prefix + " set_voting <voting> [referees] [game_id]` - Change who may vote (host or admin)\n" +
+			"• `" + prefix + " event edit|add|retire` - Fix, add or withdraw events mid-game; retired squares are replaced or made free (host or admin)\n" +
+			"• `" + prefix + " add_player <user> [game_id]` - Deal a board to a late joiner; closed events start marked (host or admin)\n" +
+			"• `" + prefix + " remove_player <user> [game_id]` - Remove a board, withdrawing its votes on open events and its wins (host or admin)\n" +
+			"• `" + prefix + " reopen_event <event_id> [keep_votes] [game_id]` - Reopen a closed event, revoking wins that relied on it (host or admin)\n" +
+			"• `" + prefix + " pack save|snapshot|list|show|delete` - Keep event lists for reuse\n\n" +
+			"**Game Information**\n" +
+			"• `" + prefix + " list_games` - List this channel's games with stats\n" +
+			"• `" + prefix + " list_events [game_id]` - List events with vote counts\n" +
+			"• `" + prefix + " my_board [game_id]` - See your own board, visible only to you\n" +
+			"• `" + prefix + " view_board <user> [game_id] [live]` - View a player's board (your own only, while boards are private); `live:True` keeps the message updated as events close\n" +
+			"• `" + prefix + " verify_boards [game_id]` - Regenerate boards from the game's seed to prove they weren't altered (host or admin)\n\n" +
+			"**Gameplay**\n" +
+			"• `" + prefix + " vote <event_id> [game_id]` - Vote that an event occurred\n" +
+			"• `" + prefix + " unvote <event_id> [game_id]` - Take back your vote while the event is open\n" +
+			"• `" + prefix + " event_panel [game_id]` - Post a panel for voting with a menu; it updates as votes come in\n" +
+			"• `" + prefix + " help [topic]` - Show this help\n\n" +
+			"• Tip: start typing in `event_id`, `game_id` or `pack` to search by description, title or name" +
+			more
+		respondEmbed(s, i, "BingoBot Commands", helpText, colorInfo, false)
+	}
+}
+
+// consensusHelp lists the consensus rules, and the one the channel's active game uses
+func consensusHelp(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate) string {
+	var b strings.Builder
+	for _, spec := range rules.ConsensusSpecs {
+		c, _ := rules.ParseConsensus(spec)
+		b.WriteString("• `" + spec + "` - " + c.Describe() + "\n")
+	}
+
+	game, err := database.GetActiveGame(ctx, parseSnowflake(i.ChannelID))
+	if err == nil && game != nil {
+		if c, err := rules.ParseConsensus(game.Consensus); err == nil {
+			b.WriteString(fmt.Sprintf("• Active game #%d uses: **%s**\n", game.ID, c.Describe()))
+		}
+	}
+	return b.String()
 }
@@ -3,11 +3,11 @@ package commands
 import (
 	"context"
 	"fmt"
-	"math"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
 )
 
 // ListEvents returns the list_events subcommand definition
@@ -18,10 +18,11 @@ func ListEvents() *discordgo.ApplicationCommandOption {
 		Description: "List all events for a game with their status and vote counts",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "game_id",
-				Description: "ID of the game to list events for (uses active game if not provided)",
-				Required:    false,
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game to list events for (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
 			},
 		},
 	}
@@ -31,7 +32,7 @@ func ListEvents() *discordgo.ApplicationCommandOption {
 func HandleListEvents(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
 	ctx := context.Background()
 
-	gameID, err := getGameIDOrActive(ctx, database, options, "game_id")
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
 	if err != nil {
 		respondError(s, i, err.Error())
 		return
@@ -56,31 +57,35 @@ func HandleListEvents(s *discordgo.Session, i *discordgo.InteractionCreate, opti
 		return
 	}
 
-	// Calculate threshold
-	playerCount, err := database.GetPlayerCountForGame(ctx, gameID)
+	consensus, err := rules.ParseConsensus(game.Consensus)
 	if err != nil {
-		respondError(s, i, "Error fetching player count: "+err.Error())
+		respondError(s, i, "Error reading consensus rule: "+err.Error())
 		return
 	}
-
-	threshold := playerCount
-	if playerCount > 3 {
-		threshold = int(math.Ceil(0.6 * float64(playerCount)))
+	eligibility, err := rules.ParseEligibility(game.Eligibility)
+	if err != nil {
+		respondError(s, i, "Error reading voting rule: "+err.Error())
+		return
 	}
 
 	var lines []string
 	title := fmt.Sprintf("Events for Game #%d: %s", gameID, game.Title)
+	lines = append(lines, fmt.Sprintf("Consensus: %s\nVoting: %s\n", consensus.Describe(), eligibility.Describe()))
 
 	for _, event := range events {
-		if event.Status == string(db.EventStatusClosed) {
+		if event.Status == string(db.EventStatusRetired) {
+			lines = append(lines, fmt.Sprintf("**#%d** ~~%s~~ (retired)", event.DisplayID, event.Description))
+		} else if event.Free {
+			lines = append(lines, fmt.Sprintf("**#%d** %s ⭐ (free)", event.DisplayID, event.Description))
+		} else if event.Status == string(db.EventStatusClosed) {
 			lines = append(lines, fmt.Sprintf("**#%d** %s ✅", event.DisplayID, event.Description))
 		} else {
-			voteCount, err := database.GetVoteCount(ctx, event.ID)
+			tally, err := database.GetEventTally(ctx, game, event.ID)
 			if err != nil {
 				respondError(s, i, "Error fetching vote count: "+err.Error())
 				return
 			}
-			lines = append(lines, fmt.Sprintf("**#%d** %s (%d/%d votes)", event.DisplayID, event.Description, voteCount, threshold))
+			lines = append(lines, fmt.Sprintf("**#%d** %s (%s)", event.DisplayID, event.Description, voteProgress(consensus, eligibility, tally)))
 		}
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
 )
 
 // ListGames returns the list_games subcommand definition
@@ -14,7 +15,7 @@ func ListGames() *discordgo.ApplicationCommandOption {
 	return &discordgo.ApplicationCommandOption{
 		Type:        discordgo.ApplicationCommandOptionSubCommand,
 		Name:        "list_games",
-		Description: "List all bingo games with their details and statistics",
+		Description: "List this channel's bingo games with their details and statistics",
 	}
 }
 
@@ -22,14 +23,14 @@ func ListGames() *discordgo.ApplicationCommandOption {
 func HandleListGames(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
 	ctx := context.Background()
 
-	games, err := database.ListGames(ctx)
+	games, err := database.ListGames(ctx, parseSnowflake(i.ChannelID))
 	if err != nil {
 		respondError(s, i, "Error fetching games: "+err.Error())
 		return
 	}
 
 	if len(games) == 0 {
-		respondSuccess(s, i, "No games found. Create one with `/bg new_game`.")
+		respondSuccess(s, i, "No games found in this channel. Create one with `/bingo new_game`.")
 		return
 	}
 
@@ -48,14 +49,33 @@ func HandleListGames(s *discordgo.Session, i *discordgo.InteractionCreate, optio
 			respondError(s, i, "Error fetching player count: "+err.Error())
 			return
 		}
+		if game.Lobby {
+			signedUp, err := database.GetLobbyPlayers(ctx, game.ID)
+			if err != nil {
+				respondError(s, i, "Error fetching lobby: "+err.Error())
+				return
+			}
+			playerCount = len(signedUp)
+		}
 
 		activeMarker := ""
 		if game.IsActive {
 			activeMarker = " **(active)**"
 		}
+		switch {
+		case game.Lobby:
+			activeMarker += " 🚪 lobby"
+		case game.State != string(db.GameStateRunning):
+			activeMarker += " " + describeState(db.GameState(game.State))
+		}
+
+		winDesc := game.WinPattern
+		if stages, err := rules.ParseStages(game.WinPattern); err == nil {
+			winDesc = describeStages(stages)
+		}
 
-		line := fmt.Sprintf("**#%d** %s%s\n  %dx%d grid | %d open, %d closed | %d players",
-			game.ID, game.Title, activeMarker, game.GridSize, game.GridSize, open, closed, playerCount)
+		line := fmt.Sprintf("**#%d** %s%s\n  %dx%d grid | %d open, %d closed | %d players | win: %s",
+			game.ID, game.Title, activeMarker, game.GridSize, game.GridSize, open, closed, playerCount, winDesc)
 		lines = append(lines, line)
 	}
 
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// liveBoardTitle titles a board message, marking the ones kept up to date
+func liveBoardTitle(displayName string, game *db.Game, live bool) string {
+	title := fmt.Sprintf("Board for %s — Game #%d: %s", displayName, game.ID, game.Title)
+	if live {
+		title += " 🔴 live"
+	}
+	return title
+}
+
+// trackBoardResponse starts keeping the board just sent as the interaction's
+// response up to date
+func trackBoardResponse(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB, gameID, userID int64) error {
+	msg, err := s.InteractionResponse(i.Interaction)
+	if err != nil {
+		return err
+	}
+	return database.TrackBoard(context.Background(), db.LiveBoard{
+		MessageID: parseSnowflake(msg.ID),
+		ChannelID: parseSnowflake(msg.ChannelID),
+		GameID:    gameID,
+		UserID:    userID,
+	})
+}
+
+// refreshLiveBoards re-renders every tracked board message in a game. Call it
+// after events close, reopen or change; a newly won stage also changes the
+// outline on every board, so all of the game's boards are refreshed. Messages
+// that have since been deleted stop being tracked.
+func refreshLiveBoards(s *discordgo.Session, database *db.DB, gameID int64) {
+	ctx := context.Background()
+	boards, err := database.GetLiveBoards(ctx, gameID)
+	if err != nil {
+		log.Printf("Error fetching live boards for game %d: %v", gameID, err)
+		return
+	}
+	if len(boards) == 0 {
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil || game == nil {
+		log.Printf("Error fetching game %d to refresh live boards: %v", gameID, err)
+		return
+	}
+
+	for _, board := range boards {
+		err := refreshLiveBoard(ctx, s, database, game, board)
+		if messageGone(err) {
+			err = database.UntrackBoard(ctx, board.MessageID)
+		}
+		if err != nil {
+			log.Printf("Error refreshing live board %d in game %d: %v", board.MessageID, gameID, err)
+		}
+	}
+}
+
+// messageGone reports whether a message edit failed because the message or its
+// channel has been deleted
+func messageGone(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil &&
+		(restErr.Message.Code == discordgo.ErrCodeUnknownMessage || restErr.Message.Code == discordgo.ErrCodeUnknownChannel)
+}
+
+// refreshLiveBoard replaces a board message's image with a fresh render
+func refreshLiveBoard(ctx context.Context, s *discordgo.Session, database *db.DB, game *db.Game, board db.LiveBoard) error {
+	imageBytes, err := renderBoard(ctx, database, game, board.UserID)
+	if err != nil {
+		return err
+	}
+
+	displayName := userDisplayName(s, fmt.Sprint(game.GuildID), fmt.Sprint(board.UserID))
+	filename := fmt.Sprintf("board_game%d_user%d.png", game.ID, board.UserID)
+	embeds := []*discordgo.MessageEmbed{{
+		Title: liveBoardTitle(displayName, game, true),
+		Color: colorInfo,
+		Image: &discordgo.MessageEmbedImage{URL: "attachment://" + filename},
+	}}
+	// An empty attachment list drops the old image in favour of the new file
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:          fmt.Sprint(board.MessageID),
+		Channel:     fmt.Sprint(board.ChannelID),
+		Embeds:      &embeds,
+		Files:       []*discordgo.File{{Name: filename, ContentType: "image/png", Reader: bytes.NewReader(imageBytes)}},
+		Attachments: &[]*discordgo.MessageAttachment{},
+	})
+	return err
+}
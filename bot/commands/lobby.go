@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// maxLobbyRoster caps how many players the lobby message mentions by name
+const maxLobbyRoster = 100
+
+// buildLobby renders a game's lobby as an embed with Join, Leave and Start buttons
+func buildLobby(ctx context.Context, database *db.DB, game *db.Game) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	players, err := database.GetLobbyPlayers(ctx, game.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	open, closed, err := database.GetEventCounts(ctx, game.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stages, err := rules.ParseStages(game.WinPattern)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desc := fmt.Sprintf("%dx%d grid | %d events | win: %s\n", game.GridSize, game.GridSize, open+closed, describeStages(stages))
+	desc += fmt.Sprintf("Press **Join** to get a board. The host (<@%d>) presses **Start** to deal boards to everyone signed up.\n\n", game.HostID)
+	desc += fmt.Sprintf("**Players (%d):**", len(players))
+	if len(players) == 0 {
+		desc += " nobody yet"
+	}
+	mentions := make([]string, 0, min(len(players), maxLobbyRoster))
+	for _, userID := range players[:min(len(players), maxLobbyRoster)] {
+		mentions = append(mentions, fmt.Sprintf("<@%d>", userID))
+	}
+	desc += " " + strings.Join(mentions, " ")
+	if len(players) > maxLobbyRoster {
+		desc += fmt.Sprintf(" …and %d more", len(players)-maxLobbyRoster)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Lobby: #%d — %s", game.ID, game.Title),
+		Description: desc,
+		Color:       colorInfo,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Join",
+				Style:    discordgo.SuccessButton,
+				CustomID: componentID(componentLobbyJoin, game.ID),
+			},
+			discordgo.Button{
+				Label:    "Leave",
+				Style:    discordgo.SecondaryButton,
+				CustomID: componentID(componentLobbyLeave, game.ID),
+			},
+			discordgo.Button{
+				Label:    "Start",
+				Style:    discordgo.PrimaryButton,
+				CustomID: componentID(componentLobbyStart, game.ID),
+			},
+		}},
+	}
+	return embed, components, nil
+}
+
+// handleLobbyJoin signs the clicking member up and refreshes the lobby
+func handleLobbyJoin(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64, database *db.DB) {
+	err := database.JoinLobby(context.Background(), gameID, parseSnowflake(i.Member.User.ID))
+	switch {
+	case errors.Is(err, db.ErrAlreadyJoined):
+		respondError(s, i, fmt.Sprintf("You're already signed up for game #%d.", gameID))
+		return
+	case errors.Is(err, db.ErrLobbyClosed):
+		respondError(s, i, fmt.Sprintf("Game #%d has already started. Ask the host to add you with `/%s add_player`.", gameID, Prefix))
+		return
+	case err != nil:
+		respondError(s, i, "Error joining game: "+err.Error())
+		return
+	}
+	log.Printf("ok %s actor=%s game_id=%d", interactionLabel(i), interactionActor(i), gameID)
+	updateLobby(s, i, database, gameID)
+}
+
+// handleLobbyLeave takes the clicking member off the roster and refreshes the lobby
+func handleLobbyLeave(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64, database *db.DB) {
+	err := database.LeaveLobby(context.Background(), gameID, parseSnowflake(i.Member.User.ID))
+	switch {
+	case errors.Is(err, db.ErrNotJoined):
+		respondError(s, i, fmt.Sprintf("You aren't signed up for game #%d.", gameID))
+		return
+	case errors.Is(err, db.ErrLobbyClosed):
+		respondError(s, i, fmt.Sprintf("Game #%d has already started. Ask the host to remove you with `/%s remove_player`.", gameID, Prefix))
+		return
+	case err != nil:
+		respondError(s, i, "Error leaving game: "+err.Error())
+		return
+	}
+	log.Printf("ok %s actor=%s game_id=%d", interactionLabel(i), interactionActor(i), gameID)
+	updateLobby(s, i, database, gameID)
+}
+
+// handleLobbyStart deals boards to the lobby's roster and opens voting
+func handleLobbyStart(s *discordgo.Session, i *discordgo.InteractionCreate, gameID int64, database *db.DB) {
+	ctx := context.Background()
+
+	game, err := getChannelGame(ctx, database, i, gameID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "start this game"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	events, err := database.GetGameEvents(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching events: "+err.Error())
+		return
+	}
+	dealtEvents := 0
+	if len(events) > 0 {
+		dealtEvents = events[len(events)-1].DisplayID
+	}
+	consensus, err := rules.ParseConsensus(game.Consensus)
+	if err != nil {
+		respondError(s, i, "Error reading consensus rule: "+err.Error())
+		return
+	}
+	eligibility, err := rules.ParseEligibility(game.Eligibility)
+	if err != nil {
+		respondError(s, i, "Error reading voting rule: "+err.Error())
+		return
+	}
+	referees, err := database.GetGameReferees(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching referees: "+err.Error())
+		return
+	}
+
+	// Boards are dealt from every event so far, like new_game; squares showing
+	// events retired in the lobby are then swapped out as a retirement would
+	var players []int64
+	var fairness fairnessMetrics
+	var unreachable error
+	err = database.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if players, err = database.StartLobby(ctx, tx, gameID, dealtEvents); err != nil {
+			return err
+		}
+		// Too few players for a fixed vote count leaves the lobby open
+		if unreachable = consensus.Validate(countVoters(eligibility, game.HostID, players, referees)); unreachable != nil {
+			return unreachable
+		}
+		game.DealtEvents = dealtEvents
+		if fairness, err = createBoards(ctx, tx, database, gameID, newBoardDeal(game, events, players)); err != nil {
+			return err
+		}
+		return database.ReplaceRetiredSquares(ctx, tx, gameID)
+	})
+	switch {
+	case errors.Is(err, db.ErrLobbyEmpty):
+		respondError(s, i, "Nobody has joined yet, so there's nobody to deal boards to.")
+		return
+	case errors.Is(err, db.ErrLobbyClosed):
+		respondError(s, i, fmt.Sprintf("Game #%d has already started.", gameID))
+		return
+	case unreachable != nil:
+		respondError(s, i, fmt.Sprintf("Can't start yet: %v. Lower the rule with `/%s set_consensus` or wait for more players.", unreachable, Prefix))
+		return
+	case err != nil:
+		respondError(s, i, "Error starting game: "+err.Error())
+		return
+	}
+
+	mentions := make([]string, len(players))
+	for n, userID := range players {
+		mentions[n] = fmt.Sprintf("<@%d>", userID)
+	}
+	log.Printf("ok bg/lobby_start actor=%s game_id=%d players=%d", i.Member.User.ID, gameID, len(players))
+	updateEmbed(s, i, fmt.Sprintf("Lobby Closed: #%d — %s", gameID, game.Title), fmt.Sprintf("%d players signed up.", len(players)), colorInfo)
+
+	desc := fmt.Sprintf("✓ Dealt boards to %d players: %s\n", len(players), strings.Join(mentions, " "))
+	desc += fmt.Sprintf("Dealing: %s — %s\n", game.Dealing, fairness)
+	desc += fmt.Sprintf("Board seed: `%d` (check with `/%s verify_boards`)\n", game.Seed, Prefix)
+	desc += fmt.Sprintf("Voting is open! See your board with `/%s my_board`.", Prefix)
+	if len(desc) > maxEmbedDescription {
+		desc = fmt.Sprintf("✓ Dealt boards to %d players.\nBoard seed: `%d`\nVoting is open!", len(players), game.Seed)
+	}
+	followupEmbed(s, i, fmt.Sprintf("Game Started: #%d — %s", gameID, game.Title), desc, colorSuccess, false)
+	refreshEventPanels(s, database, gameID, 0)
+
+	dmBoards(s, i, database, game)
+}
+
+// updateLobby re-renders the lobby message in place
+func updateLobby(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB, gameID int64) {
+	ctx := context.Background()
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil || game == nil {
+		respondError(s, i, fmt.Sprintf("Game #%d not found.", gameID))
+		return
+	}
+	embed, components, err := buildLobby(ctx, database, game)
+	if err != nil {
+		respondError(s, i, "Error refreshing lobby: "+err.Error())
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}); err != nil {
+		log.Printf("err %s actor=%s lobby update failed: %v", interactionLabel(i), interactionActor(i), err)
+	}
+}
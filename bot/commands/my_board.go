@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// MyBoard returns the my_board subcommand definition
+func MyBoard() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "my_board",
+		Description: "Show your own bingo board, visible only to you",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleMyBoard processes the my_board command
+func HandleMyBoard(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+
+	userID := parseSnowflake(i.Member.User.ID)
+	imageBytes, err := renderBoard(ctx, database, game, userID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	title := fmt.Sprintf("Your Board — Game #%d: %s", gameID, game.Title)
+	filename := fmt.Sprintf("board_game%d_user%d.png", gameID, userID)
+	if err := respondEmbedWithImage(s, i, title, colorInfo, filename, imageBytes, true); err != nil {
+		respondError(s, i, "Error sending board image: "+err.Error())
+	}
+}
+
+// dmBoards sends every player their board by direct message if the game asks
+// for it, then follows up on the interaction with anyone who couldn't be reached.
+// Call it once voting opens and the interaction has been responded to.
+func dmBoards(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB, game *db.Game) {
+	if !game.DMBoards {
+		return
+	}
+	ctx := context.Background()
+
+	boards, err := database.GetGameBoards(ctx, game.ID)
+	if err != nil {
+		followupEmbed(s, i, "Boards Not Sent", "Error fetching boards: "+err.Error(), colorError, true)
+		return
+	}
+
+	var failed []string
+	for _, board := range boards {
+		if err := dmBoard(ctx, s, database, game, board.UserID); err != nil {
+			log.Printf("err %s actor=%s game_id=%d board DM to %d failed: %v", interactionLabel(i), interactionActor(i), game.ID, board.UserID, err)
+			failed = append(failed, fmt.Sprintf("<@%d>", board.UserID))
+		}
+	}
+	log.Printf("ok %s actor=%s game_id=%d boards_dmed=%d failed=%d", interactionLabel(i), interactionActor(i), game.ID, len(boards)-len(failed), len(failed))
+
+	if len(failed) == 0 {
+		followupEmbed(s, i, "Boards Sent", fmt.Sprintf("✓ Sent %d players their boards by DM.", len(boards)), colorSuccess, false)
+		return
+	}
+	desc := fmt.Sprintf("Sent %d of %d boards by DM. Couldn't reach %s; they can see their boards with `/%s my_board`.",
+		len(boards)-len(failed), len(boards), strings.Join(failed, " "), Prefix)
+	if len(desc) > maxEmbedDescription {
+		desc = fmt.Sprintf("Sent %d of %d boards by DM. %d players couldn't be reached; they can see their boards with `/%s my_board`.",
+			len(boards)-len(failed), len(boards), len(failed), Prefix)
+	}
+	followupEmbed(s, i, "Boards Sent", desc, colorInfo, false)
+}
+
+// dmBoard sends one player their board in a direct message
+func dmBoard(ctx context.Context, s *discordgo.Session, database *db.DB, game *db.Game, userID int64) error {
+	imageBytes, err := renderBoard(ctx, database, game, userID)
+	if err != nil {
+		return err
+	}
+	channel, err := s.UserChannelCreate(fmt.Sprint(userID))
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("board_game%d_user%d.png", game.ID, userID)
+	_, err = s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       fmt.Sprintf("Your Board — Game #%d: %s", game.ID, game.Title),
+			Description: fmt.Sprintf("Voting is open! See your board any time with `/%s my_board`.", Prefix),
+			Color:       colorInfo,
+			Image:       &discordgo.MessageEmbedImage{URL: "attachment://" + filename},
+		}},
+		Files: []*discordgo.File{{Name: filename, ContentType: "image/png", Reader: bytes.NewReader(imageBytes)}},
+	})
+	return err
+}
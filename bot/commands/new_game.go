@@ -3,16 +3,14 @@ package commands
 import (
 	"context"
 	"database/sql"
-	"encoding/csv"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
 )
 
 // NewGame returns the new_game subcommand definition
@@ -26,13 +24,13 @@ func NewGame() *discordgo.ApplicationCommandOption {
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "title",
 				Description: "Name for the bingo game",
-				Required:    true,
+				Required:    false,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionInteger,
 				Name:        "grid_size",
 				Description: "Size of the grid (2-10, typically 3, 4, or 5)",
-				Required:    true,
+				Required:    false,
 				MinValue:    floatPtr(2),
 				MaxValue:    10,
 			},
@@ -40,131 +38,371 @@ func NewGame() *discordgo.ApplicationCommandOption {
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "player_ids",
 				Description: "Discord users who will participate (@player1 @player2 ...)",
-				Required:    true,
+				Required:    false,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionAttachment,
 				Name:        "events_csv",
-				Description: "CSV file containing event descriptions",
-				Required:    true,
+				Description: "CSV of events; optional header: description,category,weight,tags,free",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "game_file",
+				Description: "JSON or YAML file defining the whole game; other options override it",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "free_cell",
+				Description: "A free, pre-marked square on every board: center (odd grids) or row,col like 2,3",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "dealing",
+				Description: "standard (default) or fair: even event use, less overlap, balanced difficulty",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "standard", Value: string(db.DealStandard)},
+					{Name: "fair", Value: string(db.DealFair)},
+				},
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "pack",
+				Description:  "Saved event pack to use instead of events_csv",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "win_pattern",
+				Description: "line (default), lines:N, blackout, corners, x, plus, a mask like X.X/.X./X.X; > for stages",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "places",
+				Description: "Places awarded per stage, e.g. 3 for 1st/2nd/3rd (default 3)",
+				Required:    false,
+				MinValue:    floatPtr(1),
+				MaxValue:    10,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "consensus",
+				Description:  "Votes needed to close an event: default, fixed:N, percent:P, active:P, host, any_two",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "lobby",
+				Description: "Post Join/Leave buttons and deal boards when you press Start; player_ids become optional",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "private_boards",
+				Description: "Players only see their own board (with my_board) until the game finishes (default false)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "dm_boards",
+				Description: "DM every player their board when voting opens (default false)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "draft",
+				Description: "Create the game as a draft and start voting later with set_game_state (default false)",
+				Required:    false,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "voting",
+				Description:  "Who may vote: players (default), referees, or open:N with a spectator quorum of N",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "referees",
+				Description: "Non-players whose votes count under referees voting (@ref1 @ref2 ...)",
+				Required:    false,
 			},
 		},
 	}
 }
 
+// defaultPrizePlaces is how many places each stage awards unless new_game says otherwise
+const defaultPrizePlaces = 3
+
 // HandleNewGame processes the new_game command
 func HandleNewGame(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
 	ctx := context.Background()
 
+	if err := checkCanCreateGames(ctx, database, i); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	// A game file supplies defaults that the other options override
+	def := &gameDefinition{}
+	if attachmentID, ok := getAttachmentOption(options, "game_file"); ok {
+		url, err := attachmentURL(i, attachmentID)
+		if err != nil {
+			respondError(s, i, err.Error())
+			return
+		}
+		def, err = fetchGameFile(url)
+		if err != nil {
+			respondError(s, i, "Error reading game file: "+err.Error())
+			return
+		}
+	}
+
 	// Parse options
-	title, ok := getStringOption(options, "title")
-	if !ok {
-		respondError(s, i, "Missing required title option.")
+	title := def.Title
+	if opt, ok := getStringOption(options, "title"); ok {
+		title = opt
+	}
+	if title == "" {
+		respondError(s, i, "Missing title: set the title option or `title` in the game file.")
 		return
 	}
 
-	gridSizeInt, ok := getIntOption(options, "grid_size")
-	if !ok {
-		respondError(s, i, "Missing required grid_size option.")
+	gridSize := def.GridSize
+	if n, ok := getIntOption(options, "grid_size"); ok {
+		gridSize = int(n)
+	}
+	if gridSize == 0 {
+		respondError(s, i, "Missing grid_size: set the grid_size option or `grid_size` in the game file.")
 		return
 	}
-	gridSize := int(gridSizeInt)
 
-	playerIDsStr, ok := getStringOption(options, "player_ids")
-	if !ok {
-		respondError(s, i, "Missing required player_ids option.")
+	winPatternSpec := def.WinPattern
+	if opt, ok := getStringOption(options, "win_pattern"); ok {
+		winPatternSpec = opt
+	}
+	stages, err := rules.ParseStages(winPatternSpec)
+	if err != nil {
+		respondError(s, i, "Invalid win_pattern: "+err.Error())
 		return
 	}
+	for _, stage := range stages {
+		if err := stage.Validate(gridSize); err != nil {
+			respondError(s, i, "Invalid win_pattern: "+err.Error())
+			return
+		}
+	}
 
-	attachmentID, ok := getAttachmentOption(options, "events_csv")
-	if !ok {
-		respondError(s, i, "Missing required events_csv option.")
+	places := int64(defaultPrizePlaces)
+	if def.Places > 0 {
+		places = int64(def.Places)
+	}
+	if n, ok := getIntOption(options, "places"); ok {
+		places = n
+	}
+
+	consensusSpec := def.Consensus
+	if opt, ok := getStringOption(options, "consensus"); ok {
+		consensusSpec = opt
+	}
+	consensus, err := rules.ParseConsensus(consensusSpec)
+	if err != nil {
+		respondError(s, i, "Invalid consensus: "+err.Error())
 		return
 	}
 
-	// Parse player IDs from mentions
-	playerIDs := parseMentionsToIDs(playerIDsStr)
-	if len(playerIDs) == 0 {
-		respondError(s, i, "No valid player mentions found. Use @username format.")
+	votingSpec := def.Voting
+	if opt, ok := getStringOption(options, "voting"); ok {
+		votingSpec = opt
+	}
+	eligibility, err := rules.ParseEligibility(votingSpec)
+	if err != nil {
+		respondError(s, i, "Invalid voting: "+err.Error())
 		return
 	}
+	refereeIDs := def.Referees
+	if opt, ok := getStringOption(options, "referees"); ok {
+		refereeIDs = parseMentionsToIDs(opt)
+	}
 
-	// Fetch and parse CSV
-	resolved := i.ApplicationCommandData().Resolved
-	if resolved == nil || resolved.Attachments == nil {
-		respondError(s, i, "No attachments found in request.")
+	freeCellSpec := def.FreeCell
+	if opt, ok := getStringOption(options, "free_cell"); ok {
+		freeCellSpec = opt
+	}
+	freeCell, err := rules.ParseFreeCell(freeCellSpec, gridSize)
+	if err != nil {
+		respondError(s, i, "Invalid free_cell: "+err.Error())
 		return
 	}
-	attachment, exists := resolved.Attachments[attachmentID]
-	if !exists {
-		respondError(s, i, "Attachment not found in request.")
+
+	dealing := db.DealStandard
+	if def.Dealing != "" {
+		dealing = db.DealMode(def.Dealing)
+	}
+	if opt, ok := getStringOption(options, "dealing"); ok {
+		dealing = db.DealMode(opt)
+	}
+
+	draft := def.Draft
+	if opt := findOption(options, "draft"); opt != nil {
+		draft = opt.BoolValue()
+	}
+	lobby := def.Lobby
+	if opt := findOption(options, "lobby"); opt != nil {
+		lobby = opt.BoolValue()
+	}
+	if lobby && draft {
+		respondError(s, i, "A lobby game stays closed to votes until you press Start, so it can't also be a draft.")
 		return
 	}
-	events, err := fetchAndParseCSV(attachment.URL)
-	if err != nil {
-		respondError(s, i, "Error parsing CSV: "+err.Error())
+	privateBoards := def.PrivateBoards
+	if opt := findOption(options, "private_boards"); opt != nil {
+		privateBoards = opt.BoolValue()
+	}
+	sendBoards := def.DMBoards
+	if opt := findOption(options, "dm_boards"); opt != nil {
+		sendBoards = opt.BoolValue()
+	}
+	state := db.GameStateRunning
+	if draft || lobby {
+		state = db.GameStateDraft
+	}
+
+	// Parse player IDs from mentions
+	playerIDs := def.Players
+	if opt, ok := getStringOption(options, "player_ids"); ok {
+		playerIDs = parseMentionsToIDs(opt)
+	}
+	playerIDs = slices.Compact(slices.Sorted(slices.Values(playerIDs)))
+	if len(playerIDs) == 0 && !lobby {
+		respondError(s, i, "No valid player mentions found. Use @username format in player_ids, list `players` in the game file, or use lobby:True to let players sign up.")
+		return
+	}
+	// Lobby games are checked when they start and the roster is known
+	if !lobby {
+		if err := consensus.Validate(countVoters(eligibility, parseSnowflake(i.Member.User.ID), playerIDs, refereeIDs)); err != nil {
+			respondError(s, i, "Invalid consensus: "+err.Error())
+			return
+		}
+	}
+
+	// Fetch and parse CSV, or load a saved pack
+	events := def.Events
+	packName, usePack := getStringOption(options, "pack")
+	if usePack && findOption(options, "events_csv") != nil {
+		respondError(s, i, "Use either events_csv or pack, not both.")
+		return
+	}
+	if usePack {
+		pack, err := database.GetPack(ctx, parseSnowflake(i.GuildID), strings.TrimSpace(packName))
+		if err != nil {
+			respondError(s, i, "Error fetching pack: "+err.Error())
+			return
+		}
+		if pack == nil {
+			respondError(s, i, fmt.Sprintf("No pack named **%s** in this server. See `/%s pack list`.", packName, Prefix))
+			return
+		}
+		events, err = database.GetPackEvents(ctx, pack.ID)
+		if err != nil {
+			respondError(s, i, "Error fetching pack events: "+err.Error())
+			return
+		}
+	}
+	if attachmentID, ok := getAttachmentOption(options, "events_csv"); ok {
+		url, err := attachmentURL(i, attachmentID)
+		if err != nil {
+			respondError(s, i, err.Error())
+			return
+		}
+		events, err = fetchAndParseCSV(url)
+		if err != nil {
+			respondError(s, i, "Error parsing CSV: "+err.Error())
+			return
+		}
+	}
+	if len(events) == 0 {
+		respondError(s, i, "No events found: attach events_csv, choose a pack or list `events` in the game file.")
 		return
 	}
 
 	// Validate event count
 	minEvents := gridSize * gridSize
+	if freeCell != nil {
+		minEvents--
+	}
 	if len(events) < minEvents {
-		respondError(s, i, fmt.Sprintf("CSV must contain at least %d events for a %dx%d grid (found %d).", minEvents, gridSize, gridSize, len(events)))
+		respondError(s, i, fmt.Sprintf("At least %d events are needed for a %dx%d grid (found %d).", minEvents, gridSize, gridSize, len(events)))
 		return
 	}
 
 	// Create game
-	gameID, err := database.CreateGame(ctx, title, gridSize)
+	game := db.Game{
+		GuildID:       parseSnowflake(i.GuildID),
+		ChannelID:     parseSnowflake(i.ChannelID),
+		Title:         title,
+		GridSize:      gridSize,
+		WinPattern:    rules.FormatStages(stages),
+		PrizePlaces:   int(places),
+		Consensus:     consensus.String(),
+		HostID:        parseSnowflake(i.Member.User.ID),
+		Eligibility:   eligibility.String(),
+		State:         string(state),
+		FreeCell:      freeCell,
+		Seed:          rand.Int63(),
+		DealtEvents:   len(events),
+		Dealing:       string(dealing),
+		Lobby:         lobby,
+		PrivateBoards: privateBoards,
+		DMBoards:      sendBoards,
+	}
+	gameID, err := database.CreateGame(ctx, game)
 	if err != nil {
 		respondError(s, i, "Error creating game: "+err.Error())
 		return
 	}
+	game.ID = gameID
+	if len(refereeIDs) > 0 {
+		if err := database.SetGameReferees(ctx, gameID, refereeIDs); err != nil {
+			respondError(s, i, "Error saving referees: "+err.Error())
+			return
+		}
+	}
 
-	// Set as active if no active game exists
-	activeGame, err := database.GetActiveGame(ctx)
+	// Set as active if the channel has no active game
+	activeGame, err := database.GetActiveGame(ctx, parseSnowflake(i.ChannelID))
 	if err == nil && activeGame == nil {
 		database.SetActiveGame(ctx, gameID)
 	}
 
 	// Create game data in transaction
+	var fairness fairnessMetrics
 	err = database.WithTx(ctx, func(tx *sql.Tx) error {
 		// Create events
-		eventIDs := make([]int64, len(events))
-		for i, desc := range events {
-			displayID := i + 1
-			eventID, err := database.CreateEvent(ctx, tx, gameID, displayID, desc)
+		for i := range events {
+			events[i].GameID = gameID
+			events[i].DisplayID = i + 1
+			eventID, err := database.CreateEvent(ctx, tx, events[i])
 			if err != nil {
 				return fmt.Errorf("error creating event: %w", err)
 			}
-			eventIDs[i] = eventID
+			events[i].ID = eventID
 		}
 
-		// Distribute events to boards
-		boardAssignments := distributeEvents(eventIDs, playerIDs, gridSize)
-
-		// Create boards and squares
-		for playerID, assignments := range boardAssignments {
-			boardID, err := database.CreateBoard(ctx, tx, gameID, playerID, gridSize)
-			if err != nil {
-				return fmt.Errorf("error creating board: %w", err)
-			}
-
-			squares := make([]db.BoardSquare, 0, len(assignments))
-			for _, assign := range assignments {
-				squares = append(squares, db.BoardSquare{
-					BoardID: boardID,
-					Row:     assign.row,
-					Column:  assign.col,
-					EventID: assign.eventID,
-				})
-			}
-
-			if err := database.CreateBoardSquares(ctx, tx, boardID, squares); err != nil {
-				return fmt.Errorf("error creating board squares: %w", err)
-			}
+		// Lobby games deal boards when they start
+		if lobby {
+			return nil
 		}
 
-		return nil
+		// Deal boards from the game's seed
+		fairness, err = createBoards(ctx, tx, database, gameID, newBoardDeal(&game, events, playerIDs))
+		return err
 	})
 
 	if err != nil {
@@ -172,133 +410,76 @@ func HandleNewGame(s *discordgo.Session, i *discordgo.InteractionCreate, options
 		return
 	}
 
-	titleText := fmt.Sprintf("Game Created: #%d — %s", gameID, title)
-	msg := fmt.Sprintf("%dx%d grid | %d events | %d players", gridSize, gridSize, len(events), len(playerIDs))
-	respondEmbed(s, i, titleText, msg, colorSuccess, false)
-}
-
-// fetchAndParseCSV fetches a CSV file from URL and parses event descriptions
-func fetchAndParseCSV(url string) ([]string, error) {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch CSV: %s", resp.Status)
-	}
-	defer resp.Body.Close()
-
-	// Limit read size to 1MB
-	limitedReader := &io.LimitedReader{
-		R: resp.Body,
-		N: 1 << 20, // 1MB
-	}
-
-	reader := csv.NewReader(limitedReader)
-	reader.TrimLeadingSpace = true
-
-	var events []string
-	firstRow := true
-
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
+	if lobby {
+		for _, userID := range playerIDs {
+			if err := database.JoinLobby(ctx, gameID, userID); err != nil {
+				respondError(s, i, "Error signing up players: "+err.Error())
+				return
+			}
 		}
+		embed, components, err := buildLobby(ctx, database, &game)
 		if err != nil {
-			return nil, err
+			respondError(s, i, "Error building lobby: "+err.Error())
+			return
 		}
-
-		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
-			continue
-		}
-
-		// Skip header if first cell is "description"
-		if firstRow && strings.ToLower(strings.TrimSpace(record[0])) == "description" {
-			firstRow = false
-			continue
-		}
-		firstRow = false
-
-		events = append(events, strings.TrimSpace(record[0]))
-	}
-
-	return events, nil
-}
-
-type assignment struct {
-	eventID int64
-	row     int
-	col     int
-}
-
-// distributeEvents implements the balanced distribution algorithm
-func distributeEvents(eventIDs []int64, playerIDs []int64, gridSize int) map[int64][]assignment {
-	cellsPerBoard := gridSize * gridSize
-	totalCells := cellsPerBoard * len(playerIDs)
-
-	// If more events than total cells, shuffle to avoid front-of-file bias
-	if len(eventIDs) > totalCells {
-		shuffled := make([]int64, len(eventIDs))
-		copy(shuffled, eventIDs)
-		rand.Shuffle(len(shuffled), func(i, j int) {
-			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+			},
 		})
-		eventIDs = shuffled
+		return
 	}
 
-	// Initialize boards
-	boards := make(map[int64][]assignment)
-	for _, pid := range playerIDs {
-		boards[pid] = make([]assignment, 0, cellsPerBoard)
+	titleText := fmt.Sprintf("Game Created: #%d — %s", gameID, title)
+	msg := fmt.Sprintf("%dx%d grid | %d events | %d players | win: %s\nConsensus: %s\nVoting: %s", gridSize, gridSize, len(events), len(playerIDs), describeStages(stages), consensus.Describe(), describeVoting(eligibility, refereeIDs))
+	if summary := describeEventMix(events); summary != "" {
+		msg += "\n" + summary
+	}
+	if freeCell != nil {
+		msg += fmt.Sprintf("\n⭐ Free square at row %d, column %d on every board", freeCell.Row+1, freeCell.Col+1)
+	}
+	msg += fmt.Sprintf("\nDealing: %s — %s", dealing, fairness)
+	msg += fmt.Sprintf("\nBoard seed: `%d` (check with `/%s verify_boards`)", game.Seed, Prefix)
+	if privateBoards {
+		msg += fmt.Sprintf("\n🔒 Private boards: players see their own with `/%s my_board` until the game finishes", Prefix)
+	}
+	if state == db.GameStateDraft {
+		msg += fmt.Sprintf("\n📝 Draft: voting opens with `/%s set_game_state running`.", Prefix)
 	}
+	respondEmbed(s, i, titleText, msg, colorSuccess, false)
 
-	// Track which events each board has
-	boardHasEvent := make(map[int64]map[int64]bool)
-	for _, pid := range playerIDs {
-		boardHasEvent[pid] = make(map[int64]bool)
+	if state == db.GameStateRunning {
+		dmBoards(s, i, database, &game)
 	}
+}
 
-	// Round-robin pass: ensure each event used at least once
-	playerIdx := 0
-	for _, eventID := range eventIDs {
-		placed := false
-		attempts := 0
-		for attempts < len(playerIDs) {
-			pid := playerIDs[playerIdx]
-			if len(boards[pid]) < cellsPerBoard && !boardHasEvent[pid][eventID] {
-				row := len(boards[pid]) / gridSize
-				col := len(boards[pid]) % gridSize
-				boards[pid] = append(boards[pid], assignment{eventID, row, col})
-				boardHasEvent[pid][eventID] = true
-				placed = true
-				playerIdx = (playerIdx + 1) % len(playerIDs)
-				break
-			}
-			playerIdx = (playerIdx + 1) % len(playerIDs)
-			attempts++
+// describeEventMix summarises the categories, weights and free squares of imported events
+func describeEventMix(events []db.Event) string {
+	categories := make(map[string]bool)
+	weighted, free := 0, 0
+	for _, event := range events {
+		if event.Category != "" {
+			categories[event.Category] = true
 		}
-		if !placed {
-			break // All boards full
+		if event.Weight != 1 {
+			weighted++
 		}
-	}
-
-	// Fill remaining cells with random events (no duplicates per board)
-	for _, pid := range playerIDs {
-		for len(boards[pid]) < cellsPerBoard {
-			// Pick random event not yet on this board
-			eventID := eventIDs[rand.Intn(len(eventIDs))]
-			if !boardHasEvent[pid][eventID] {
-				row := len(boards[pid]) / gridSize
-				col := len(boards[pid]) % gridSize
-				boards[pid] = append(boards[pid], assignment{eventID, row, col})
-				boardHasEvent[pid][eventID] = true
-			}
+		if event.Free {
+			free++
 		}
 	}
 
-	return boards
+	var parts []string
+	if len(categories) > 0 {
+		parts = append(parts, fmt.Sprintf("%d categories", len(categories)))
+	}
+	if weighted > 0 {
+		parts = append(parts, fmt.Sprintf("%d weighted events", weighted))
+	}
+	if free > 0 {
+		parts = append(parts, fmt.Sprintf("%d free squares (pre-marked)", free))
+	}
+	return strings.Join(parts, " | ")
 }
@@ -0,0 +1,360 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+const (
+	// maxPackName keeps pack names short enough for autocomplete and embeds
+	maxPackName = 50
+	// maxEmbedDescription is Discord's limit on an embed's description, less some headroom
+	maxEmbedDescription = 3900
+)
+
+// Pack returns the pack subcommand group definition
+func Pack() *discordgo.ApplicationCommandOption {
+	nameOption := func(description string, autocomplete bool) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "name",
+			Description:  description,
+			Required:     true,
+			MaxLength:    maxPackName,
+			Autocomplete: autocomplete,
+		}
+	}
+	replaceOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionBoolean,
+		Name:        "replace",
+		Description: "Overwrite a pack with the same name (its creator or an admin)",
+		Required:    false,
+	}
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:        "pack",
+		Description: "Saved event packs for new_game",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save",
+				Description: "Save a CSV of events as a pack",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Name for the pack", false),
+					{
+						Type:        discordgo.ApplicationCommandOptionAttachment,
+						Name:        "events_csv",
+						Description: "CSV of events, in the same format as new_game",
+						Required:    true,
+					},
+					replaceOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "snapshot",
+				Description: "Save a game's current events as a pack",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Name for the pack", false),
+					{
+						Type:         discordgo.ApplicationCommandOptionInteger,
+						Name:         "game_id",
+						Description:  "ID of the game to copy (uses active game if not provided)",
+						Required:     false,
+						Autocomplete: true,
+					},
+					replaceOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List this server's event packs",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show the events in a pack",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Pack to show", true),
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a pack (its creator or an admin)",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Pack to delete", true),
+				},
+			},
+		},
+	}
+}
+
+// HandlePack routes the pack subcommand group
+func HandlePack(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	if len(options) == 0 {
+		return
+	}
+	subCmd := options[0]
+
+	switch subCmd.Name {
+	case "save":
+		handlePackSave(s, i, subCmd.Options, database)
+	case "snapshot":
+		handlePackSnapshot(s, i, subCmd.Options, database)
+	case "list":
+		handlePackList(s, i, database)
+	case "show":
+		handlePackShow(s, i, subCmd.Options, database)
+	case "delete":
+		handlePackDelete(s, i, subCmd.Options, database)
+	}
+}
+
+// handlePackSave processes pack save
+func handlePackSave(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	attachmentID, ok := getAttachmentOption(options, "events_csv")
+	if !ok {
+		respondError(s, i, "Missing required events_csv option.")
+		return
+	}
+	url, err := attachmentURL(i, attachmentID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	events, err := fetchAndParseCSV(url)
+	if err != nil {
+		respondError(s, i, "Error parsing CSV: "+err.Error())
+		return
+	}
+
+	savePack(ctx, s, i, options, database, events, "")
+}
+
+// handlePackSnapshot processes pack snapshot
+func handlePackSnapshot(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	gameEvents, err := database.GetGameEvents(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching events: "+err.Error())
+		return
+	}
+
+	// Retired events were withdrawn from the game, so they stay out of the pack
+	var events []db.Event
+	for _, event := range gameEvents {
+		if event.Status != string(db.EventStatusRetired) {
+			events = append(events, event)
+		}
+	}
+
+	savePack(ctx, s, i, options, database, events, fmt.Sprintf(" from game #%d", gameID))
+}
+
+// savePack validates the name and permissions, then stores events as a pack
+func savePack(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB, events []db.Event, source string) {
+	if err := checkCanCreateGames(ctx, database, i); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	name, err := packName(options)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if len(events) == 0 {
+		respondError(s, i, "There are no events to save.")
+		return
+	}
+
+	replace := false
+	if opt := findOption(options, "replace"); opt != nil {
+		replace = opt.BoolValue()
+	}
+	if replace {
+		existing, err := database.GetPack(ctx, parseSnowflake(i.GuildID), name)
+		if err != nil {
+			respondError(s, i, "Error fetching pack: "+err.Error())
+			return
+		}
+		if existing != nil {
+			if err := checkPackOwner(ctx, database, i, existing, "replace it"); err != nil {
+				respondError(s, i, err.Error())
+				return
+			}
+		}
+	}
+
+	_, err = database.SavePack(ctx, db.EventPack{
+		GuildID:   parseSnowflake(i.GuildID),
+		Name:      name,
+		CreatedBy: parseSnowflake(i.Member.User.ID),
+	}, events, replace)
+	if errors.Is(err, db.ErrPackExists) {
+		respondError(s, i, fmt.Sprintf("A pack named **%s** already exists. Pick another name, or use `replace:True`.", name))
+		return
+	}
+	if err != nil {
+		respondError(s, i, "Error saving pack: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Saved %d events%s as **%s**.\nUse it with `/%s new_game pack:%s`.", len(events), source, name, Prefix, name)
+	if summary := describeEventMix(events); summary != "" {
+		desc += "\n" + summary
+	}
+	respondEmbed(s, i, "Pack Saved", desc, colorSuccess, false)
+}
+
+// handlePackList processes pack list
+func handlePackList(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB) {
+	ctx := context.Background()
+
+	packs, err := database.ListPacks(ctx, parseSnowflake(i.GuildID))
+	if err != nil {
+		respondError(s, i, "Error fetching packs: "+err.Error())
+		return
+	}
+	if len(packs) == 0 {
+		respondSuccess(s, i, fmt.Sprintf("No event packs saved yet. Save one with `/%s pack save` or `/%s pack snapshot`.", Prefix, Prefix))
+		return
+	}
+
+	desc := ""
+	for n, pack := range packs {
+		line := fmt.Sprintf("**%s** — %d events, saved by <@%d> on %s\n", pack.Name, pack.EventCount, pack.CreatedBy, pack.CreatedAt.Format("2 Jan 2006"))
+		if len(desc)+len(line) > maxEmbedDescription {
+			desc += fmt.Sprintf("…and %d more", len(packs)-n)
+			break
+		}
+		desc += line
+	}
+	respondEmbed(s, i, "Event Packs", strings.TrimSuffix(desc, "\n"), colorInfo, false)
+}
+
+// handlePackShow processes pack show
+func handlePackShow(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	pack, err := lookupPack(ctx, database, i, options)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	events, err := database.GetPackEvents(ctx, pack.ID)
+	if err != nil {
+		respondError(s, i, "Error fetching pack events: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("%d events, saved by <@%d>", pack.EventCount, pack.CreatedBy)
+	if summary := describeEventMix(events); summary != "" {
+		desc += "\n" + summary
+	}
+	desc += "\n"
+	for n, event := range events {
+		line := fmt.Sprintf("\n%d. %s", n+1, event.Description)
+		var details []string
+		if event.Category != "" {
+			details = append(details, event.Category)
+		}
+		if event.Weight != 1 {
+			details = append(details, fmt.Sprintf("weight %g", event.Weight))
+		}
+		if len(event.Tags) > 0 {
+			details = append(details, "#"+strings.Join(event.Tags, " #"))
+		}
+		if len(details) > 0 {
+			line += " — " + strings.Join(details, ", ")
+		}
+		if event.Free {
+			line += " ⭐ (free)"
+		}
+		if len(desc)+len(line) > maxEmbedDescription {
+			desc += fmt.Sprintf("\n…and %d more", len(events)-n)
+			break
+		}
+		desc += line
+	}
+	respondEmbed(s, i, "Pack: "+pack.Name, desc, colorInfo, false)
+}
+
+// handlePackDelete processes pack delete
+func handlePackDelete(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	pack, err := lookupPack(ctx, database, i, options)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if err := checkPackOwner(ctx, database, i, pack, "delete it"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	if err := database.DeletePack(ctx, pack.ID); err != nil {
+		respondError(s, i, "Error deleting pack: "+err.Error())
+		return
+	}
+	respondEmbed(s, i, "Pack Deleted", fmt.Sprintf("✓ Deleted pack **%s** (%d events). Games created from it are unaffected.", pack.Name, pack.EventCount), colorSuccess, false)
+}
+
+// packName reads and validates the name option
+func packName(options []*discordgo.ApplicationCommandInteractionDataOption) (string, error) {
+	name, _ := getStringOption(options, "name")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("the pack name can't be empty")
+	}
+	if len([]rune(name)) > maxPackName {
+		return "", fmt.Errorf("pack names can be at most %d characters", maxPackName)
+	}
+	return name, nil
+}
+
+// lookupPack fetches the pack named by the name option in the invoking guild
+func lookupPack(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) (*db.EventPack, error) {
+	name, err := packName(options)
+	if err != nil {
+		return nil, err
+	}
+	pack, err := database.GetPack(ctx, parseSnowflake(i.GuildID), name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pack: %w", err)
+	}
+	if pack == nil {
+		return nil, fmt.Errorf("no pack named **%s** in this server. See `/%s pack list`", name, Prefix)
+	}
+	return pack, nil
+}
+
+// checkPackOwner returns an error unless the invoking member saved the pack or is an admin
+func checkPackOwner(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, pack *db.EventPack, action string) error {
+	if pack.CreatedBy == parseSnowflake(i.Member.User.ID) {
+		return nil
+	}
+	admin, err := isAdmin(ctx, database, i)
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !admin {
+		return fmt.Errorf("only the member who saved **%s** (<@%d>) or an admin can %s", pack.Name, pack.CreatedBy, action)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// managerPermissions are the Discord permissions that always grant admin rights over games
+const managerPermissions = discordgo.PermissionAdministrator | discordgo.PermissionManageGuild
+
+// isGuildManager reports whether the invoking member can manage the server
+func isGuildManager(i *discordgo.InteractionCreate) bool {
+	return i.Member != nil && i.Member.Permissions&managerPermissions != 0
+}
+
+// isAdmin reports whether the invoking member can manage every game in the guild:
+// server managers, and members holding the guild's configured admin role
+func isAdmin(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate) (bool, error) {
+	if isGuildManager(i) {
+		return true, nil
+	}
+	adminRoleID, err := adminRole(ctx, database, i)
+	if err != nil || adminRoleID == 0 {
+		return false, err
+	}
+	for _, role := range i.Member.Roles {
+		if parseSnowflake(role) == adminRoleID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// adminRole returns the guild's admin role ID, or 0 if none is configured
+func adminRole(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate) (int64, error) {
+	settings, err := database.GetGuildSettings(ctx, parseSnowflake(i.GuildID))
+	if err != nil || settings == nil {
+		return 0, err
+	}
+	return settings.AdminRoleID, nil
+}
+
+// checkGameManager returns an error unless the invoking member hosts the game or is an admin.
+// Games predating recorded hosts can only be managed by admins.
+func checkGameManager(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, game *db.Game, action string) error {
+	if game.HostID != 0 && game.HostID == parseSnowflake(i.Member.User.ID) {
+		return nil
+	}
+	admin, err := isAdmin(ctx, database, i)
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if admin {
+		return nil
+	}
+	if game.HostID == 0 {
+		return fmt.Errorf("game #%d has no recorded host, so only admins can %s", game.ID, action)
+	}
+	return fmt.Errorf("only the host (<@%d>) or an admin can %s", game.HostID, action)
+}
+
+// checkCanCreateGames returns an error if the guild restricts game creation to admins.
+// Once an admin role is configured only admins may create games; until then anyone can.
+func checkCanCreateGames(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate) error {
+	adminRoleID, err := adminRole(ctx, database, i)
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if adminRoleID == 0 {
+		return nil
+	}
+	admin, err := isAdmin(ctx, database, i)
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !admin {
+		return fmt.Errorf("only members with the <@&%d> role or Manage Server can create games here", adminRoleID)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// RemovePlayer returns the remove_player subcommand definition
+func RemovePlayer() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "remove_player",
+		Description: "Remove a player's board; their votes on open events are withdrawn (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "The Discord user leaving the game",
+				Required:    true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleRemovePlayer processes the remove_player command
+func HandleRemovePlayer(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	opt := findOption(options, "user")
+	if opt == nil {
+		respondError(s, i, "Missing required user option.")
+		return
+	}
+	userID := parseSnowflake(opt.UserValue(s).ID)
+
+	game, err := playerManagedGame(ctx, database, i, options, "remove players")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	result, err := database.RemovePlayer(ctx, game.ID, userID)
+	switch {
+	case errors.Is(err, db.ErrNotInGame):
+		respondError(s, i, fmt.Sprintf("<@%d> doesn't have a board in game #%d.", userID, game.ID))
+		return
+	case errors.Is(err, db.ErrLastPlayer):
+		respondError(s, i, fmt.Sprintf("<@%d> is the last player in game #%d. Delete the game instead.", userID, game.ID))
+		return
+	case err != nil:
+		respondError(s, i, "Error removing player: "+err.Error())
+		return
+	}
+
+	players, err := database.GetPlayerCountForGame(ctx, game.ID)
+	if err != nil {
+		respondError(s, i, "Error counting players: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Removed <@%d> and their board from game #%d (**%s**).", userID, game.ID, game.Title)
+	if result.WithdrawnVotes > 0 {
+		desc += fmt.Sprintf("\n%d of their votes on open events were withdrawn; votes on closed events stand.", result.WithdrawnVotes)
+	}
+	desc += "\n" + describeThreshold(game, players)
+
+	stages, _ := rules.ParseStages(game.WinPattern)
+	if len(result.Revoked) > 0 {
+		desc += "\n\n**Revoked wins:**\n" + formatWins(result.Revoked, stages)
+	}
+	color := colorSuccess
+	if len(result.Closed) > 0 {
+		desc += "\n\n🎉 **Now marked as occurred:**"
+		for _, event := range result.Closed {
+			desc += fmt.Sprintf("\n#%d — %s", event.DisplayID, event.Description)
+		}
+	}
+	if len(result.Wins) > 0 {
+		desc += "\n\n🏆 **BINGO!**\n" + formatWins(result.Wins, stages)
+		color = colorWin
+	}
+	respondEmbed(s, i, "Player Removed", desc, color, false)
+	refreshEventPanels(s, database, game.ID, 0)
+	refreshLiveBoards(s, database, game.ID)
+}
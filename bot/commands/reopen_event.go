@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// ReopenEvent returns the reopen_event subcommand definition
+func ReopenEvent() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "reopen_event",
+		Description: "Reopen a wrongly closed event and revoke wins that depended on it (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "event_id",
+				Description:  "ID of the closed event to reopen",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "keep_votes",
+				Description: "Keep the existing votes if they fall short of consensus, instead of clearing them (default false)",
+				Required:    false,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleReopenEvent processes the reopen_event command
+func HandleReopenEvent(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	displayID, ok := getIntOption(options, "event_id")
+	if !ok {
+		respondError(s, i, "Missing required event_id option.")
+		return
+	}
+	keepVotes := false
+	if opt := findOption(options, "keep_votes"); opt != nil {
+		keepVotes = opt.BoolValue()
+	}
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "reopen events"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if err := checkEditable(game, "reopen events"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	event, err := database.GetEventByDisplayID(ctx, gameID, int(displayID))
+	if err != nil {
+		respondError(s, i, "Error fetching event: "+err.Error())
+		return
+	}
+	if event == nil {
+		respondError(s, i, fmt.Sprintf("Event #%d not found in the current game.", displayID))
+		return
+	}
+	if event.Free {
+		respondError(s, i, fmt.Sprintf("Event #%d is a free square and is always marked.", displayID))
+		return
+	}
+	if event.Status != string(db.EventStatusClosed) {
+		respondError(s, i, fmt.Sprintf("Event #%d is not closed.", displayID))
+		return
+	}
+
+	revoked, err := database.ReopenEvent(ctx, gameID, event.ID, !keepVotes)
+	if errors.Is(err, db.ErrEventNotClosed) {
+		respondError(s, i, fmt.Sprintf("Event #%d is not closed.", displayID))
+		return
+	}
+	if errors.Is(err, db.ErrVotesStillReached) {
+		respondError(s, i, fmt.Sprintf("Event #%d's votes still meet the consensus rule, and nobody can vote on it twice, so it could never close again. Reopen it without keep_votes instead.", displayID))
+		return
+	}
+	if err != nil {
+		respondError(s, i, "Error reopening event: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Event #%d (**%s**) is open again.", displayID, event.Description)
+	if keepVotes {
+		desc += "\nExisting votes were kept; the next vote re-checks consensus."
+	} else {
+		desc += "\nIts votes were cleared."
+	}
+	if len(revoked) > 0 {
+		stages, _ := rules.ParseStages(game.WinPattern)
+		desc += "\n\n**Revoked wins:**\n" + formatWins(revoked, stages)
+	}
+	respondEmbed(s, i, "Event Reopened", desc, colorSuccess, false)
+	refreshEventPanels(s, database, gameID, 0)
+	refreshLiveBoards(s, database, gameID)
+}
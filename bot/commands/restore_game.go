@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// RestoreGame returns the restore_game subcommand definition
+func RestoreGame() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "restore_game",
+		Description: "Bring back a deleted game before it is purged",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the deleted game to restore",
+				Required:     true,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleRestoreGame processes the restore_game command
+func HandleRestoreGame(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	gameID, ok := getIntOption(options, "game_id")
+	if !ok {
+		respondError(s, i, "Missing required game_id option.")
+		return
+	}
+
+	// getChannelGame hides deleted games, so look the game up directly
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	if game == nil || game.ChannelID != parseSnowflake(i.ChannelID) {
+		respondError(s, i, fmt.Sprintf("Game #%d not found in this channel. It may already have been purged.", gameID))
+		return
+	}
+	if game.DeletedAt == nil {
+		respondError(s, i, fmt.Sprintf("Game #%d (**%s**) hasn't been deleted.", gameID, game.Title))
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "restore this game"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	if err := database.RestoreGame(ctx, gameID); err != nil {
+		respondError(s, i, "Error restoring game: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Game #%d (**%s**) restored.", gameID, game.Title)
+
+	// Make it active if the channel has nothing else going
+	activeGame, err := database.GetActiveGame(ctx, game.ChannelID)
+	if err == nil && activeGame == nil {
+		if err := database.SetActiveGame(ctx, gameID); err == nil {
+			desc += "\nIt is now the active game."
+		}
+	}
+	respondEmbed(s, i, "Game Restored", desc, colorSuccess, false)
+}
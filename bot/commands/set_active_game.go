@@ -13,13 +13,14 @@ func SetActiveGame() *discordgo.ApplicationCommandOption {
 	return &discordgo.ApplicationCommandOption{
 		Type:        discordgo.ApplicationCommandOptionSubCommand,
 		Name:        "set_active_game",
-		Description: "Set which game is currently active",
+		Description: "Set which game is currently active in this channel",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "game_id",
-				Description: "ID of the game to make active",
-				Required:    true,
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game to make active",
+				Required:     true,
+				Autocomplete: true,
 			},
 		},
 	}
@@ -35,14 +36,20 @@ func HandleSetActiveGame(s *discordgo.Session, i *discordgo.InteractionCreate, o
 		return
 	}
 
-	// Verify game exists
-	game, err := database.GetGame(ctx, gameID)
+	// Verify game exists in this channel
+	game, err := getChannelGame(ctx, database, i, gameID)
 	if err != nil {
-		respondError(s, i, "Error checking game: "+err.Error())
+		respondError(s, i, err.Error())
 		return
 	}
-	if game == nil {
-		respondError(s, i, fmt.Sprintf("Game #%d not found.", gameID))
+
+	if err := checkGameManager(ctx, database, i, game, "make this game active"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	if game.State == string(db.GameStateArchived) {
+		respondError(s, i, fmt.Sprintf("Game #%d is archived. Unarchive it with `/%s set_game_state finished` first.", gameID, Prefix))
 		return
 	}
 
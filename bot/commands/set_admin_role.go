@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// SetAdminRole returns the set_admin_role subcommand definition
+func SetAdminRole() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "set_admin_role",
+		Description: "Choose the role that can manage every game in this server (Manage Server only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionRole,
+				Name:        "role",
+				Description: "Role to make bingo admins (leave empty to clear)",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// HandleSetAdminRole processes the set_admin_role command
+func HandleSetAdminRole(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	if !isGuildManager(i) {
+		respondError(s, i, "Only members with the Manage Server permission can set the admin role.")
+		return
+	}
+
+	var roleID int64
+	if opt := findOption(options, "role"); opt != nil {
+		roleID = parseSnowflake(fmt.Sprint(opt.Value))
+	}
+
+	if err := database.SetAdminRole(ctx, parseSnowflake(i.GuildID), roleID); err != nil {
+		respondError(s, i, "Error saving admin role: "+err.Error())
+		return
+	}
+
+	desc := "✓ Admin role cleared. Hosts manage their own games; members with Manage Server manage all of them."
+	if roleID != 0 {
+		desc = fmt.Sprintf("✓ Members with <@&%d> can now create games and manage every game in this server.", roleID)
+	}
+	respondEmbed(s, i, "Admin Role Updated", desc, colorSuccess, false)
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// SetConsensus returns the set_consensus subcommand definition
+func SetConsensus() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "set_consensus",
+		Description: "Change how many votes close an event (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "consensus",
+				Description:  "default, fixed:N, percent:P, active:P, host, any_two",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleSetConsensus processes the set_consensus command
+func HandleSetConsensus(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	spec, ok := getStringOption(options, "consensus")
+	if !ok {
+		respondError(s, i, "Missing required consensus option.")
+		return
+	}
+	consensus, err := rules.ParseConsensus(spec)
+	if err != nil {
+		respondError(s, i, "Invalid consensus: "+err.Error())
+		return
+	}
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+
+	if err := checkGameManager(ctx, database, i, game, "change the consensus rule"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if err := checkEditable(game, "change the consensus rule"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if consensus.Kind == rules.ConsensusHost && game.HostID == 0 {
+		respondError(s, i, fmt.Sprintf("Game #%d has no recorded host, so it can't use host confirmation.", gameID))
+		return
+	}
+
+	// Lobby games are checked when they start and the roster is known
+	if !game.Lobby {
+		players, err := database.GetGamePlayerIDs(ctx, gameID)
+		if err != nil {
+			respondError(s, i, "Error fetching players: "+err.Error())
+			return
+		}
+		referees, err := database.GetGameReferees(ctx, gameID)
+		if err != nil {
+			respondError(s, i, "Error fetching referees: "+err.Error())
+			return
+		}
+		eligibility, err := rules.ParseEligibility(game.Eligibility)
+		if err != nil {
+			respondError(s, i, "Error reading voting rule: "+err.Error())
+			return
+		}
+		if err := consensus.Validate(countVoters(eligibility, game.HostID, players, referees)); err != nil {
+			respondError(s, i, "Invalid consensus: "+err.Error())
+			return
+		}
+	}
+
+	closed, wins, err := database.SetGameConsensus(ctx, gameID, consensus.String())
+	if err != nil {
+		respondError(s, i, "Error updating consensus rule: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Game #%d (**%s**) now closes events with %s.\nVotes already cast count toward the new rule.", gameID, game.Title, consensus.Describe())
+	color := colorSuccess
+	if len(closed) > 0 {
+		desc += "\n\n🎉 **Now marked as occurred:**"
+		for _, event := range closed {
+			desc += fmt.Sprintf("\n#%d — %s", event.DisplayID, event.Description)
+		}
+	}
+	if len(wins) > 0 {
+		stages, _ := rules.ParseStages(game.WinPattern)
+		desc += "\n\n🏆 **BINGO!**\n" + formatWins(wins, stages)
+		color = colorWin
+	}
+	respondEmbed(s, i, "Consensus Updated", desc, color, false)
+	refreshEventPanels(s, database, gameID, 0)
+	if len(closed) > 0 {
+		refreshLiveBoards(s, database, gameID)
+	}
+}
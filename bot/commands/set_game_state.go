@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// SetGameState returns the set_game_state subcommand definition
+func SetGameState() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "set_game_state",
+		Description: "Start, pause, resume, finish or archive a game (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "state",
+				Description: "The state to move the game to",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "running — accept votes", Value: string(db.GameStateRunning)},
+					{Name: "paused — reject votes until resumed", Value: string(db.GameStatePaused)},
+					{Name: "finished — freeze boards and record the result", Value: string(db.GameStateFinished)},
+					{Name: "archived — hide a finished game", Value: string(db.GameStateArchived)},
+				},
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleSetGameState processes the set_game_state command
+func HandleSetGameState(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	stateStr, ok := getStringOption(options, "state")
+	if !ok {
+		respondError(s, i, "Missing required state option.")
+		return
+	}
+	to := db.GameState(stateStr)
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "change the game's state"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	from := db.GameState(game.State)
+	if err := database.SetGameState(ctx, gameID, to); err != nil {
+		if errors.Is(err, db.ErrLobbyOpen) {
+			respondError(s, i, fmt.Sprintf("Game #%d is still in its lobby. Press **Start** on the lobby message to deal boards and open voting.", gameID))
+			return
+		}
+		if errors.Is(err, db.ErrInvalidTransition) {
+			respondError(s, i, fmt.Sprintf("Game #%d is %s, so it can't become %s. From here it can become: %s.", gameID, from, to, formatStates(from.Next())))
+			return
+		}
+		respondError(s, i, "Error changing game state: "+err.Error())
+		return
+	}
+
+	desc := fmt.Sprintf("✓ Game #%d (**%s**) is now %s.", gameID, game.Title, describeState(to))
+	switch {
+	case to == db.GameStateRunning && from == db.GameStateDraft:
+		desc += "\nVoting is open!"
+	case to == db.GameStateRunning:
+		desc += "\nVoting has resumed."
+	case to == db.GameStatePaused:
+		desc += "\nVotes are rejected until the game resumes."
+	case to == db.GameStateFinished && from != db.GameStateArchived:
+		desc += "\nBoards are frozen."
+		wins, err := database.GetWins(ctx, gameID)
+		if err != nil {
+			desc += "\n(Error fetching results: " + err.Error() + ")"
+		} else if len(wins) > 0 {
+			stages, _ := rules.ParseStages(game.WinPattern)
+			desc += "\n\n**Final results:**\n" + formatWins(wins, stages)
+		} else {
+			desc += "\nNobody won this time."
+		}
+	case to == db.GameStateArchived:
+		desc += "\nIt no longer appears in `list_games`; use its ID to view or unarchive it."
+	}
+	respondEmbed(s, i, "Game State Updated", desc, colorSuccess, false)
+	refreshEventPanels(s, database, gameID, 0)
+
+	if to == db.GameStateRunning && from == db.GameStateDraft {
+		dmBoards(s, i, database, game)
+	}
+}
+
+// describeState renders a game state with an icon, e.g. "⏸️ paused"
+func describeState(state db.GameState) string {
+	icons := map[db.GameState]string{
+		db.GameStateDraft:    "📝",
+		db.GameStateRunning:  "▶️",
+		db.GameStatePaused:   "⏸️",
+		db.GameStateFinished: "🏁",
+		db.GameStateArchived: "🗄️",
+	}
+	return icons[state] + " " + string(state)
+}
+
+// formatStates lists states for display
+func formatStates(states []db.GameState) string {
+	if len(states) == 0 {
+		return "nothing"
+	}
+	names := make([]string, len(states))
+	for n, state := range states {
+		names[n] = string(state)
+	}
+	return strings.Join(names, ", ")
+}
+
+// checkAcceptsVotes returns an error explaining why a game isn't taking votes, if it isn't
+func checkAcceptsVotes(game *db.Game) error {
+	switch db.GameState(game.State) {
+	case db.GameStateRunning:
+		return nil
+	case db.GameStateDraft:
+		return fmt.Errorf("game #%d is still a draft; voting opens when the host starts it", game.ID)
+	case db.GameStatePaused:
+		return fmt.Errorf("game #%d is paused; votes are accepted again once the host resumes it", game.ID)
+	}
+	return fmt.Errorf("game #%d is %s, so its votes are final", game.ID, game.State)
+}
+
+// checkEditable returns an error if the game is finished or archived and can no longer change
+func checkEditable(game *db.Game, action string) error {
+	if db.GameState(game.State).Editable() {
+		return nil
+	}
+	return fmt.Errorf("game #%d is %s, so you can't %s", game.ID, game.State, action)
+}
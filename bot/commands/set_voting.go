@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// SetVoting returns the set_voting subcommand definition
+func SetVoting() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "set_voting",
+		Description: "Change who may vote on events (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "voting",
+				Description:  "players, referees, or open:N with a spectator quorum of N",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "referees",
+				Description: "Replace the game's referees (@ref1 @ref2 ...)",
+				Required:    false,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleSetVoting processes the set_voting command
+func HandleSetVoting(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	spec, ok := getStringOption(options, "voting")
+	if !ok {
+		respondError(s, i, "Missing required voting option.")
+		return
+	}
+	eligibility, err := rules.ParseEligibility(spec)
+	if err != nil {
+		respondError(s, i, "Invalid voting: "+err.Error())
+		return
+	}
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "change who may vote"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if err := checkEditable(game, "change who may vote"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	if refereesStr, ok := getStringOption(options, "referees"); ok {
+		refereeIDs := parseMentionsToIDs(refereesStr)
+		if len(refereeIDs) == 0 && strings.TrimSpace(refereesStr) != "" {
+			respondError(s, i, "No valid referee mentions found. Use @username format.")
+			return
+		}
+		if err := database.SetGameReferees(ctx, gameID, refereeIDs); err != nil {
+			respondError(s, i, "Error saving referees: "+err.Error())
+			return
+		}
+	}
+	if err := database.SetGameEligibility(ctx, gameID, eligibility.String()); err != nil {
+		respondError(s, i, "Error updating voting rule: "+err.Error())
+		return
+	}
+
+	referees, err := database.GetGameReferees(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching referees: "+err.Error())
+		return
+	}
+	desc := fmt.Sprintf("✓ Votes on game #%d (**%s**) now count from %s.", gameID, game.Title, describeVoting(eligibility, referees))
+	if eligibility.Kind == rules.EligibilityReferees && len(referees) == 0 {
+		desc += "\nNo referees are set yet; add them with the `referees` option."
+	}
+	respondEmbed(s, i, "Voting Updated", desc, colorSuccess, false)
+	refreshEventPanels(s, database, gameID, 0)
+}
+
+// describeVoting explains an eligibility rule, naming the referees when they can vote
+func describeVoting(e rules.Eligibility, referees []int64) string {
+	if e.Kind != rules.EligibilityReferees || len(referees) == 0 {
+		return e.Describe()
+	}
+	mentions := make([]string, len(referees))
+	for n, userID := range referees {
+		mentions[n] = fmt.Sprintf("<@%d>", userID)
+	}
+	return e.Describe() + " (" + strings.Join(mentions, ", ") + ")"
+}
+
+// countVoters counts the distinct users whose votes count toward consensus:
+// the players, the host and, when referees may vote, the referees
+func countVoters(e rules.Eligibility, hostID int64, players, referees []int64) int {
+	voters := make(map[int64]bool, len(players)+len(referees)+1)
+	for _, userID := range players {
+		voters[userID] = true
+	}
+	if hostID != 0 {
+		voters[hostID] = true
+	}
+	if e.Kind == rules.EligibilityReferees {
+		for _, userID := range referees {
+			voters[userID] = true
+		}
+	}
+	return len(voters)
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// Unvote returns the unvote subcommand definition
+func Unvote() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "unvote",
+		Description: "Take back your vote on an event that is still open",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "event_id",
+				Description:  "ID of the event to remove your vote from",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleUnvote processes the unvote command
+func HandleUnvote(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+	userID := parseSnowflake(i.Member.User.ID)
+
+	displayID, ok := getIntOption(options, "event_id")
+	if !ok {
+		respondError(s, i, "Missing required event_id option.")
+		return
+	}
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	if err := checkAcceptsVotes(game); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	event, err := database.GetEventByDisplayID(ctx, gameID, int(displayID))
+	if err != nil {
+		respondError(s, i, "Error fetching event: "+err.Error())
+		return
+	}
+	if event == nil {
+		respondError(s, i, fmt.Sprintf("Event #%d not found in the current game.", displayID))
+		return
+	}
+	switch db.EventStatus(event.Status) {
+	case db.EventStatusClosed:
+		respondError(s, i, fmt.Sprintf("Event #%d has already closed, so votes can't be removed. Ask the host to reopen it.", displayID))
+		return
+	case db.EventStatusRetired:
+		respondError(s, i, fmt.Sprintf("Event #%d has been retired, so its votes no longer count.", displayID))
+		return
+	}
+
+	removed, err := database.DeleteVote(ctx, event.ID, userID)
+	if err != nil {
+		respondError(s, i, "Error removing vote: "+err.Error())
+		return
+	}
+	if !removed {
+		respondError(s, i, fmt.Sprintf("You haven't voted for event #%d.", displayID))
+		return
+	}
+
+	log.Printf("ok bg/unvote actor=%s game_id=%d event_display_id=%d", i.Member.User.ID, gameID, displayID)
+	desc := fmt.Sprintf("✓ Removed your vote for event #%d: **%s**", displayID, event.Description)
+	respondEmbed(s, i, "Vote Removed", desc, colorSuccess, false)
+	refreshEventPanels(s, database, gameID, 0)
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"regexp"
 	"strconv"
 	"time"
@@ -47,6 +49,38 @@ func getAttachmentOption(options []*discordgo.ApplicationCommandInteractionDataO
 	return "", false
 }
 
+// maxAttachmentSize caps how much of an uploaded file is read
+const maxAttachmentSize = 1 << 20 // 1MB
+
+// attachmentURL looks up the URL of an attachment option's file
+func attachmentURL(i *discordgo.InteractionCreate, attachmentID string) (string, error) {
+	resolved := i.ApplicationCommandData().Resolved
+	if resolved == nil || resolved.Attachments == nil {
+		return "", fmt.Errorf("no attachments found in request")
+	}
+	attachment, exists := resolved.Attachments[attachmentID]
+	if !exists {
+		return "", fmt.Errorf("attachment not found in request")
+	}
+	return attachment.URL, nil
+}
+
+// fetchAttachment downloads an uploaded file, reading at most maxAttachmentSize bytes
+func fetchAttachment(url string) ([]byte, error) {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch file: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize))
+}
+
 // parseMentionsToIDs extracts Discord user IDs from mention strings
 func parseMentionsToIDs(s string) []int64 {
 	matches := mentionRegex.FindAllStringSubmatch(s, -1)
@@ -59,12 +93,6 @@ func parseMentionsToIDs(s string) []int64 {
 	return ids
 }
 
-// parseUserID converts Discord snowflake ID to int64
-func parseUserID(snowflake string) int64 {
-	id, _ := strconv.ParseInt(snowflake, 10, 64)
-	return id
-}
-
 // userDisplayName returns the member's guild display name (nickname) if set; otherwise the username.
 // Falls back to the userID string if neither can be retrieved.
 func userDisplayName(s *discordgo.Session, guildID, userID string) string {
@@ -90,20 +118,43 @@ func userDisplayName(s *discordgo.Session, guildID, userID string) string {
 	return userID
 }
 
-// getGameIDOrActive returns specified game_id or active game
-func getGameIDOrActive(ctx context.Context, database *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption, optionName string) (int64, error) {
+// parseSnowflake converts a Discord snowflake (user, guild or channel ID) to int64
+func parseSnowflake(snowflake string) int64 {
+	id, _ := strconv.ParseInt(snowflake, 10, 64)
+	return id
+}
+
+// getChannelGame fetches a game and checks it belongs to the interaction's channel.
+// Soft-deleted games are treated as missing.
+func getChannelGame(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, gameID int64) (*db.Game, error) {
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching game: %w", err)
+	}
+	if game == nil || game.ChannelID != parseSnowflake(i.ChannelID) || game.DeletedAt != nil {
+		return nil, fmt.Errorf("game #%d not found in this channel", gameID)
+	}
+	return game, nil
+}
+
+// getGameIDOrActive returns specified game_id or the channel's active game
+func getGameIDOrActive(ctx context.Context, database *db.DB, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, optionName string) (int64, error) {
 	for _, opt := range options {
 		if opt.Name == optionName {
-			return opt.IntValue(), nil
+			game, err := getChannelGame(ctx, database, i, opt.IntValue())
+			if err != nil {
+				return 0, err
+			}
+			return game.ID, nil
 		}
 	}
 
-	game, err := database.GetActiveGame(ctx)
+	game, err := database.GetActiveGame(ctx, parseSnowflake(i.ChannelID))
 	if err != nil {
 		return 0, fmt.Errorf("error fetching active game: %w", err)
 	}
 	if game == nil {
-		return 0, fmt.Errorf("no active game found. Please specify a game_id or set an active game")
+		return 0, fmt.Errorf("no active game found in this channel. Please specify a game_id or set an active game")
 	}
 	return game.ID, nil
 }
@@ -139,38 +190,47 @@ func respondEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, title, d
 	})
 }
 
-// respondError sends an ephemeral error message using an embed
-func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	data := i.ApplicationCommandData()
-	sub := ""
-	if len(data.Options) > 0 {
-		sub = data.Options[0].Name
+// interactionLabel describes an interaction for logging: command/subcommand or component custom ID
+func interactionLabel(i *discordgo.InteractionCreate) string {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		data := i.ApplicationCommandData()
+		sub := ""
+		if len(data.Options) > 0 {
+			sub = data.Options[0].Name
+			if data.Options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup && len(data.Options[0].Options) > 0 {
+				sub += "/" + data.Options[0].Options[0].Name
+			}
+		}
+		return data.Name + "/" + sub
+	case discordgo.InteractionMessageComponent:
+		return i.MessageComponentData().CustomID
 	}
-	actor := ""
+	return ""
+}
+
+// interactionActor returns the invoking member's user ID, if any
+func interactionActor(i *discordgo.InteractionCreate) string {
 	if i.Member != nil && i.Member.User != nil {
-		actor = i.Member.User.ID
+		return i.Member.User.ID
 	}
-	log.Printf("err %s/%s actor=%s msg=%q", data.Name, sub, actor, message)
+	return ""
+}
+
+// respondError sends an ephemeral error message using an embed
+func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+	log.Printf("err %s actor=%s msg=%q", interactionLabel(i), interactionActor(i), message)
 	respondEmbed(s, i, "Error", message, colorError, true)
 }
 
 // respondSuccess sends a non-ephemeral info message using an embed
 func respondSuccess(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	data := i.ApplicationCommandData()
-	sub := ""
-	if len(data.Options) > 0 {
-		sub = data.Options[0].Name
-	}
-	actor := ""
-	if i.Member != nil && i.Member.User != nil {
-		actor = i.Member.User.ID
-	}
-	log.Printf("ok %s/%s actor=%s msg=%q", data.Name, sub, actor, message)
+	log.Printf("ok %s actor=%s msg=%q", interactionLabel(i), interactionActor(i), message)
 	respondEmbed(s, i, "", message, colorInfo, false)
 }
 
-// respondEmbedWithImage sends an embed with an attached image file
-func respondEmbedWithImage(s *discordgo.Session, i *discordgo.InteractionCreate, title string, color int, filename string, imageBytes []byte) error {
+// respondEmbedWithImage sends an embed with an attached image file, with optional ephemeral flag
+func respondEmbedWithImage(s *discordgo.Session, i *discordgo.InteractionCreate, title string, color int, filename string, imageBytes []byte, ephemeral bool) error {
 	embed := &discordgo.MessageEmbed{
 		Title:     title,
 		Color:     color,
@@ -180,10 +240,16 @@ func respondEmbedWithImage(s *discordgo.Session, i *discordgo.InteractionCreate,
 		},
 	}
 
+	flags := discordgo.MessageFlags(0)
+	if ephemeral {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+
 	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  flags,
 			Files: []*discordgo.File{
 				{
 					Name:        filename,
@@ -194,3 +260,89 @@ func respondEmbedWithImage(s *discordgo.Session, i *discordgo.InteractionCreate,
 		},
 	})
 }
+
+// followupBoards sends board image embeds and their files as a public follow-up
+// to an already acknowledged interaction
+func followupBoards(s *discordgo.Session, i *discordgo.InteractionCreate, boards []*discordgo.MessageEmbed, files []*discordgo.File) {
+	if len(boards) == 0 {
+		return
+	}
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds: boards,
+		Files:  files,
+	}); err != nil {
+		log.Printf("err %s actor=%s followup failed: %v", interactionLabel(i), interactionActor(i), err)
+	}
+}
+
+// followupEmbedWithBoards sends an embed and board images as a follow-up to an already acknowledged interaction
+func followupEmbedWithBoards(s *discordgo.Session, i *discordgo.InteractionCreate, title, desc string, color int, ephemeral bool, boards []*discordgo.MessageEmbed, files []*discordgo.File) {
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: desc,
+		Color:       color,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	flags := discordgo.MessageFlags(0)
+	if ephemeral {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds: append([]*discordgo.MessageEmbed{embed}, boards...),
+		Files:  files,
+		Flags:  flags,
+	}); err != nil {
+		log.Printf("err %s actor=%s followup failed: %v", interactionLabel(i), interactionActor(i), err)
+	}
+}
+
+// followupEmbed sends an embed as a follow-up to an already acknowledged interaction
+func followupEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, title, desc string, color int, ephemeral bool) {
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: desc,
+		Color:       color,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	flags := discordgo.MessageFlags(0)
+	if ephemeral {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Flags:  flags,
+	}); err != nil {
+		log.Printf("err %s actor=%s followup failed: %v", interactionLabel(i), interactionActor(i), err)
+	}
+}
+
+// updateEmbed replaces the message a component belongs to with an embed, removing its components
+func updateEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, title, desc string, color int) {
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: desc,
+		Color:       color,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			// An empty (not nil) slice clears the components
+			Components: []discordgo.MessageComponent{},
+		},
+	}); err != nil {
+		log.Printf("err %s actor=%s update failed: %v", interactionLabel(i), interactionActor(i), err)
+	}
+}
+
+// updateError replaces a component's message with an error
+func updateError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+	log.Printf("err %s actor=%s msg=%q", interactionLabel(i), interactionActor(i), message)
+	updateEmbed(s, i, "Error", message, colorError)
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+)
+
+// maxVerifyMismatches is how many differing squares verify_boards lists
+const maxVerifyMismatches = 15
+
+// VerifyBoards returns the verify_boards subcommand definition
+func VerifyBoards() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "verify_boards",
+		Description: "Regenerate a game's boards from its seed and compare them with the stored boards (host or admin)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	}
+}
+
+// HandleVerifyBoards processes the verify_boards command
+func HandleVerifyBoards(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
+	ctx := context.Background()
+
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil {
+		respondError(s, i, "Error fetching game: "+err.Error())
+		return
+	}
+	if err := checkGameManager(ctx, database, i, game, "verify its boards"); err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if game.DealtEvents == 0 {
+		respondError(s, i, fmt.Sprintf("Game #%d was dealt before boards were seeded, so its boards can't be regenerated.", gameID))
+		return
+	}
+
+	report, err := verifyBoards(ctx, database, game)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	color := colorSuccess
+	if report.mismatches > 0 {
+		color = colorError
+	}
+	respondEmbed(s, i, fmt.Sprintf("Board Verification: #%d — %s", game.ID, game.Title), report.String(), color, false)
+}
+
+// verifyReport summarises how stored boards compare with their regeneration
+type verifyReport struct {
+	seed       int64
+	dealing    string
+	boards     int
+	late       int // boards dealt to late joiners, each from its own seed
+	events     int
+	mismatches int
+	retired    int      // squares changed by retiring an event, which is expected
+	lines      []string // the first differing squares
+}
+
+func (r verifyReport) String() string {
+	boards := fmt.Sprintf("%d boards", r.boards)
+	if r.late > 0 {
+		boards += fmt.Sprintf(", %d dealt to late joiners", r.late)
+	}
+	desc := fmt.Sprintf("Seed `%d` | %s dealing | %s | dealt from events #1–#%d\n", r.seed, r.dealing, boards, r.events)
+	if r.mismatches == 0 {
+		desc += "✅ Every board matches what the seed generates."
+	} else {
+		desc += fmt.Sprintf("❌ %d squares differ from what the seed generates:\n%s", r.mismatches, strings.Join(r.lines, "\n"))
+		if r.mismatches > len(r.lines) {
+			desc += fmt.Sprintf("\n…and %d more", r.mismatches-len(r.lines))
+		}
+	}
+	if r.retired > 0 {
+		desc += fmt.Sprintf("\n%d squares have since changed because their event was retired.", r.retired)
+	}
+	return desc
+}
+
+// verifyBoards regenerates a game's boards from its seed and diffs them against
+// the stored squares
+func verifyBoards(ctx context.Context, database *db.DB, game *db.Game) (verifyReport, error) {
+	events, err := database.GetGameEvents(ctx, game.ID)
+	if err != nil {
+		return verifyReport{}, fmt.Errorf("error fetching events: %w", err)
+	}
+	boards, err := database.GetGameBoards(ctx, game.ID)
+	if err != nil {
+		return verifyReport{}, fmt.Errorf("error fetching boards: %w", err)
+	}
+	stored, err := database.GetGameSquares(ctx, game.ID)
+	if err != nil {
+		return verifyReport{}, fmt.Errorf("error fetching boards: %w", err)
+	}
+	removed, err := database.GetRemovedPlayers(ctx, game.ID)
+	if err != nil {
+		return verifyReport{}, fmt.Errorf("error fetching removed players: %w", err)
+	}
+
+	// Events added after dealing played no part in it
+	byID := make(map[int64]db.Event, len(events))
+	displayIDs := make(map[int64]int, len(events))
+	var dealt []db.Event
+	for _, event := range events {
+		byID[event.ID] = event
+		displayIDs[event.ID] = event.DisplayID
+		if event.DisplayID <= game.DealtEvents {
+			dealt = append(dealt, event)
+		}
+	}
+
+	// The original deal covered everyone dealt in with the game, including players
+	// removed since; late joiners' boards were each dealt separately
+	var playerIDs []int64
+	for _, board := range boards {
+		if board.Seed == 0 {
+			playerIDs = append(playerIDs, board.UserID)
+		}
+	}
+	for _, player := range removed {
+		if player.DealtWithGame {
+			playerIDs = append(playerIDs, player.UserID)
+		}
+	}
+	expected := newBoardDeal(game, dealt, playerIDs).boards()
+
+	report := verifyReport{seed: game.Seed, dealing: game.Dealing, boards: len(boards), events: game.DealtEvents}
+	describe := func(kind db.SquareKind, eventID int64) string {
+		if kind == db.SquareKindFree {
+			return "FREE"
+		}
+		return fmt.Sprintf("#%d", displayIDs[eventID])
+	}
+
+	for _, board := range boards {
+		userID := board.UserID
+		assignments := expected[userID]
+		if board.Seed != 0 {
+			report.late++
+			assignments = lateBoardDeal(game, events, userID, board.Seed, board.DealtEvents).boards()[userID]
+		}
+		want := make(map[[2]int]assignment, len(assignments))
+		for _, assign := range assignments {
+			want[[2]int{assign.row, assign.col}] = assign
+		}
+
+		for _, square := range stored[userID] {
+			assign, ok := want[[2]int{square.Row, square.Column}]
+			wantKind := db.SquareKindEvent
+			if assign.free {
+				wantKind = db.SquareKindFree
+			}
+			if ok && square.Kind == wantKind && square.EventID == assign.eventID {
+				continue
+			}
+			if ok && !assign.free && byID[assign.eventID].Status == string(db.EventStatusRetired) {
+				report.retired++
+				continue
+			}
+
+			report.mismatches++
+			if len(report.lines) < maxVerifyMismatches {
+				expectedSquare := "nothing"
+				if ok {
+					expectedSquare = describe(wantKind, assign.eventID)
+				}
+				report.lines = append(report.lines, fmt.Sprintf("<@%d> row %d, column %d: has %s, seed gives %s",
+					userID, square.Row+1, square.Column+1, describe(square.Kind, square.EventID), expectedSquare))
+			}
+		}
+	}
+	return report, nil
+}
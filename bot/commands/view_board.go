@@ -3,9 +3,11 @@ package commands
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
 )
 
 // ViewBoard returns the view_board subcommand definition
@@ -22,9 +24,16 @@ func ViewBoard() *discordgo.ApplicationCommandOption {
 				Required:    true,
 			},
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "game_id",
-				Description: "ID of the game (uses active game if not provided)",
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "live",
+				Description: "Keep this message updated as events close instead of posting new images (default false)",
 				Required:    false,
 			},
 		},
@@ -41,13 +50,13 @@ func HandleViewBoard(s *discordgo.Session, i *discordgo.InteractionCreate, optio
 	for _, opt := range options {
 		if opt.Name == "user" {
 			userSnowflake = opt.UserValue(s).ID
-			userID = parseUserID(userSnowflake)
+			userID = parseSnowflake(userSnowflake)
 			break
 		}
 	}
 
 	// Get game ID
-	gameID, err := getGameIDOrActive(ctx, database, options, "game_id")
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
 	if err != nil {
 		respondError(s, i, err.Error())
 		return
@@ -60,41 +69,123 @@ func HandleViewBoard(s *discordgo.Session, i *discordgo.InteractionCreate, optio
 		return
 	}
 
-	// Get board
-	board, squares, err := database.GetUserBoard(ctx, gameID, userID)
+	// Private boards stay hidden from other players until the game finishes
+	viewerID := parseSnowflake(i.Member.User.ID)
+	if !boardsRevealed(game) && userID != viewerID {
+		respondError(s, i, fmt.Sprintf("Boards in game #%d are private until it finishes. See your own with `/%s my_board`.", gameID, Prefix))
+		return
+	}
+
+	live := false
+	if opt := findOption(options, "live"); opt != nil {
+		live = opt.BoolValue()
+	}
+	if live && !boardsRevealed(game) {
+		respondError(s, i, fmt.Sprintf("Live boards are posted for everyone to see, so they aren't available while game #%d's boards are private.", gameID))
+		return
+	}
+
+	imageBytes, err := renderBoard(ctx, database, game, userID)
 	if err != nil {
-		respondError(s, i, "Error fetching board: "+err.Error())
+		respondError(s, i, err.Error())
 		return
 	}
-	if board == nil {
-		respondError(s, i, fmt.Sprintf("No board found for <@%d> in game #%d.", userID, gameID))
+
+	// Create title and filename
+	displayName := userDisplayName(s, i.GuildID, userSnowflake)
+	title := liveBoardTitle(displayName, game, live)
+	filename := fmt.Sprintf("board_game%d_user%d.png", gameID, userID)
+
+	// Send embed with image, only to its owner while boards are private
+	private := !boardsRevealed(game)
+	if err := respondEmbedWithImage(s, i, title, colorInfo, filename, imageBytes, private); err != nil {
+		respondError(s, i, "Error sending board image: "+err.Error())
 		return
 	}
 
+	if live {
+		if err := trackBoardResponse(s, i, database, gameID, userID); err != nil {
+			followupEmbed(s, i, "Error", "Board posted, but it won't update: "+err.Error(), colorError, true)
+			return
+		}
+		log.Printf("ok %s actor=%s game_id=%d live_board_user=%d", interactionLabel(i), interactionActor(i), gameID, userID)
+	}
+}
+
+// boardsRevealed reports whether a game's boards are visible to everyone: always
+// for public boards, and once the game finishes for private ones
+func boardsRevealed(game *db.Game) bool {
+	state := db.GameState(game.State)
+	return !game.PrivateBoards || state == db.GameStateFinished || state == db.GameStateArchived
+}
+
+// renderBoard draws a player's board as a PNG, outlining the pattern for the
+// stage currently in play, or the last one once all are won. Its errors are
+// suitable for showing to the user.
+func renderBoard(ctx context.Context, database *db.DB, game *db.Game, userID int64) ([]byte, error) {
+	stages, err := rules.ParseStages(game.WinPattern)
+	if err != nil {
+		return nil, fmt.Errorf("error reading win pattern: %w", err)
+	}
+	stage, err := database.GetCurrentStage(ctx, game.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching current stage: %w", err)
+	}
+	pattern := stages[len(stages)-1]
+	if stage > 0 {
+		pattern = stages[stage-1]
+	}
+	return renderBoardPattern(ctx, database, game, userID, pattern)
+}
+
+// renderBoardPattern draws a player's board as a PNG against the given pattern,
+// striking through the lines that complete it, tinting those one square away
+// and ringing the square marked last
+func renderBoardPattern(ctx context.Context, database *db.DB, game *db.Game, userID int64, pattern rules.Pattern) ([]byte, error) {
+	board, squares, err := database.GetUserBoard(ctx, game.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching board: %w", err)
+	}
+	if board == nil {
+		return nil, fmt.Errorf("no board found for <@%d> in game #%d", userID, game.ID)
+	}
+
 	// Build grid from squares
 	gridSize := board.GridSize
 	grid := make([][]db.BoardSquareWithEvent, gridSize)
+	marked := make([][]bool, gridSize)
 	for i := range grid {
 		grid[i] = make([]db.BoardSquareWithEvent, gridSize)
+		marked[i] = make([]bool, gridSize)
 	}
-	for _, sq := range squares {
+	var overlay BoardOverlay
+	var recent *db.BoardSquareWithEvent
+	for n, sq := range squares {
 		grid[sq.Row][sq.Column] = sq
+		marked[sq.Row][sq.Column] = sq.Marked()
+		if sq.Marked() && sq.EventClosedAt != nil && (recent == nil || closedAfter(sq, *recent)) {
+			recent = &squares[n]
+		}
 	}
+	if recent != nil {
+		overlay.Recent = &rules.Cell{Row: recent.Row, Col: recent.Column}
+	}
+	lines := pattern.ScoringLines(gridSize)
+	overlay.Won = rules.LinesMissing(marked, lines, 0)
+	overlay.OneAway = rules.LinesMissing(marked, lines, 1)
 
-	// Generate board image
-	imageBytes, err := GenerateBoardImage(grid, gridSize)
+	imageBytes, err := GenerateBoardImage(grid, gridSize, pattern, overlay)
 	if err != nil {
-		respondError(s, i, "Error generating board image: "+err.Error())
-		return
+		return nil, fmt.Errorf("error generating board image: %w", err)
 	}
+	return imageBytes, nil
+}
 
-	// Create title and filename
-	displayName := userDisplayName(s, i.GuildID, userSnowflake)
-	title := fmt.Sprintf("Board for %s — Game #%d: %s", displayName, gameID, game.Title)
-	filename := fmt.Sprintf("board_game%d_user%d.png", gameID, userID)
-
-	// Send embed with image
-	if err := respondEmbedWithImage(s, i, title, colorInfo, filename, imageBytes); err != nil {
-		respondError(s, i, "Error sending board image: "+err.Error())
+// closedAfter reports whether a's event closed after b's. Events closed in the
+// same second are ordered by ID, which follows the order they closed in.
+func closedAfter(a, b db.BoardSquareWithEvent) bool {
+	if !a.EventClosedAt.Equal(*b.EventClosedAt) {
+		return a.EventClosedAt.After(*b.EventClosedAt)
 	}
+	return a.EventID > b.EventID
 }
@@ -2,13 +2,13 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"math"
-	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
 )
 
 // Vote returns the vote subcommand definition
@@ -19,16 +19,18 @@ func Vote() *discordgo.ApplicationCommandOption {
 		Description: "Vote that an event has occurred",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "event_id",
-				Description: "ID of the event to vote for",
-				Required:    true,
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "event_id",
+				Description:  "ID of the event to vote for",
+				Required:     true,
+				Autocomplete: true,
 			},
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "game_id",
-				Description: "ID of the game (uses active game if not provided)",
-				Required:    false,
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "game_id",
+				Description:  "ID of the game (uses active game if not provided)",
+				Required:     false,
+				Autocomplete: true,
 			},
 		},
 	}
@@ -37,7 +39,7 @@ func Vote() *discordgo.ApplicationCommandOption {
 // HandleVote processes the vote command
 func HandleVote(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, database *db.DB) {
 	ctx := context.Background()
-	userID := parseUserID(i.Member.User.ID)
+	userID := parseSnowflake(i.Member.User.ID)
 
 	// Parse options
 	displayID, ok := getIntOption(options, "event_id")
@@ -47,184 +49,86 @@ func HandleVote(s *discordgo.Session, i *discordgo.InteractionCreate, options []
 	}
 
 	// Get game ID (either specified or active)
-	gameID, err := getGameIDOrActive(ctx, database, options, "game_id")
+	gameID, err := getGameIDOrActive(ctx, database, i, options, "game_id")
 	if err != nil {
 		respondError(s, i, err.Error())
 		return
 	}
 
-	// Look up event by display_id
-	event, err := database.GetEventByDisplayID(ctx, gameID, int(displayID))
+	result, err := castVote(ctx, database, gameID, int(displayID), userID)
 	if err != nil {
-		respondError(s, i, "Error fetching event: "+err.Error())
-		return
-	}
-	if event == nil {
-		respondError(s, i, fmt.Sprintf("Event #%d not found in the current game.", displayID))
-		return
-	}
-
-	// Check if event is already closed
-	if event.Status == string(db.EventStatusClosed) {
-		respondError(s, i, fmt.Sprintf("Event #%d has already been marked as occurred.", displayID))
-		return
-	}
-
-	// Record the vote (will fail if already voted due to PRIMARY KEY constraint)
-	if err := database.CreateVote(ctx, event.ID, userID); err != nil {
-		// Check if this is a duplicate vote constraint violation
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "PRIMARY KEY") {
-			respondError(s, i, fmt.Sprintf("You have already voted for event #%d.", displayID))
-			return
-		}
-		respondError(s, i, "Error recording vote: "+err.Error())
-		return
-	}
-
-	// Get updated vote count
-	voteCount, err := database.GetVoteCount(ctx, event.ID)
-	if err != nil {
-		respondError(s, i, "Vote recorded, but error checking vote count: "+err.Error())
+		respondError(s, i, err.Error())
 		return
 	}
 
-	// Calculate threshold based on player count
-	playerCount, err := database.GetPlayerCountForGame(ctx, gameID)
-	if err != nil {
-		respondError(s, i, "Vote recorded, but error fetching player count: "+err.Error())
-		return
-	}
+	log.Printf("ok bg/vote actor=%s game_id=%d event_display_id=%d closed=%t", i.Member.User.ID, gameID, displayID, result.Closed)
+	title, desc, color := formatVote(result)
+	respondEmbed(s, i, title, desc, color, false)
 
-	threshold := playerCount
-	if playerCount > 3 {
-		threshold = int(math.Ceil(0.6 * float64(playerCount)))
-	}
+	// Rendering boards can outlast the interaction deadline, so they follow the announcement
+	boards, files := winnerBoards(ctx, s, i, database, gameID, result.Wins, result.Stages)
+	followupBoards(s, i, boards, files)
 
-	response := fmt.Sprintf("✓ Voted for event #%d: **%s**\nCurrent votes: %d/%d", displayID, event.Description, voteCount, threshold)
-
-	// Close event if threshold reached
-	if voteCount >= threshold {
-		if err := database.UpdateEventStatus(ctx, event.ID, db.EventStatusClosed); err != nil {
-			respondError(s, i, "Vote recorded, but error closing event: "+err.Error())
-			return
-		}
-		response += "\n🎉 Event has been marked as occurred!"
-
-		// Check for winners
-		winners, err := checkWinners(ctx, database, gameID)
-		if err != nil {
-			response += "\n(Error checking for winners: " + err.Error() + ")"
-		} else if len(winners) > 0 {
-			response += "\n\n🏆 **BINGO!** Winners: "
-			for idx, winnerID := range winners {
-				if idx > 0 {
-					response += ", "
-				}
-				response += fmt.Sprintf("<@%d>", winnerID)
-			}
-		}
+	refreshEventPanels(s, database, gameID, 0)
+	if result.Closed {
+		refreshLiveBoards(s, database, gameID)
 	}
-
-	log.Printf("ok bg/vote actor=%s game_id=%d event_display_id=%d closed=%t", i.Member.User.ID, gameID, displayID, voteCount >= threshold)
-	title := "Vote Recorded"
-	color := colorSuccess
-	if voteCount >= threshold {
-		title = fmt.Sprintf("Event Closed: #%d — %s", displayID, event.Description)
-		color = colorWin
-	}
-	respondEmbed(s, i, title, response, color, false)
 }
 
-// checkWinners checks all boards for bingo (row, column, or diagonal)
-func checkWinners(ctx context.Context, database *db.DB, gameID int64) ([]int64, error) {
-	playerIDs, err := database.GetGamePlayerIDs(ctx, gameID)
-	if err != nil {
-		return nil, err
-	}
-
-	var winners []int64
-	for _, playerID := range playerIDs {
-		if hasWon, err := checkPlayerWin(ctx, database, gameID, playerID); err != nil {
-			return nil, err
-		} else if hasWon {
-			winners = append(winners, playerID)
+// castVote records a user's vote through the database, translating its errors
+// into messages suitable for showing to the user
+func castVote(ctx context.Context, database *db.DB, gameID int64, displayID int, userID int64) (*db.VoteResult, error) {
+	result, err := database.CastVote(ctx, gameID, displayID, userID)
+	switch {
+	case errors.Is(err, db.ErrEventNotFound):
+		return nil, fmt.Errorf("event #%d not found in the current game", displayID)
+	case errors.Is(err, db.ErrEventClosed):
+		return nil, fmt.Errorf("event #%d has already been marked as occurred", displayID)
+	case errors.Is(err, db.ErrEventRetired):
+		return nil, fmt.Errorf("event #%d has been retired by the host", displayID)
+	case errors.Is(err, db.ErrAlreadyVoted):
+		return nil, fmt.Errorf("you have already voted for event #%d", displayID)
+	case errors.Is(err, db.ErrNotPlayer):
+		return nil, fmt.Errorf("you don't have a board in game #%d, and only its players can vote", gameID)
+	case errors.Is(err, db.ErrGameNotRunning):
+		game, gameErr := database.GetGame(ctx, gameID)
+		if gameErr != nil || game == nil {
+			return nil, fmt.Errorf("game #%d isn't accepting votes", gameID)
 		}
+		return nil, checkAcceptsVotes(game)
+	case errors.Is(err, db.ErrNotReferee):
+		return nil, fmt.Errorf("you aren't a player or referee in game #%d, so you can't vote on its events", gameID)
+	case err != nil:
+		return nil, fmt.Errorf("error recording vote: %w", err)
 	}
-
-	return winners, nil
+	return result, nil
 }
 
-// checkPlayerWin checks if a single player has won
-func checkPlayerWin(ctx context.Context, database *db.DB, gameID, playerID int64) (bool, error) {
-	board, squares, err := database.GetUserBoard(ctx, gameID, playerID)
-	if err != nil {
-		return false, err
+// formatVote renders a vote outcome as an embed title, description and color
+func formatVote(r *db.VoteResult) (string, string, int) {
+	voted := "Voted"
+	if r.Spectator {
+		voted = "Spectator vote"
 	}
-	if board == nil {
-		return false, nil
-	}
-
-	// Build grid
-	gridSize := board.GridSize
-	grid := make([][]bool, gridSize)
-	for i := range grid {
-		grid[i] = make([]bool, gridSize)
-	}
-	for _, sq := range squares {
-		grid[sq.Row][sq.Column] = (sq.EventStatus == string(db.EventStatusClosed))
-	}
-
-	// Check rows
-	for row := 0; row < gridSize; row++ {
-		allClosed := true
-		for col := 0; col < gridSize; col++ {
-			if !grid[row][col] {
-				allClosed = false
-				break
-			}
-		}
-		if allClosed {
-			return true, nil
-		}
+	response := fmt.Sprintf("✓ %s for event #%d: **%s**\nCurrent votes: %s", voted, r.Event.DisplayID, r.Event.Description, voteProgress(r.Consensus, r.Eligibility, r.Tally))
+	if !r.Closed {
+		return "Vote Recorded", response, colorSuccess
 	}
 
-	// Check columns
-	for col := 0; col < gridSize; col++ {
-		allClosed := true
-		for row := 0; row < gridSize; row++ {
-			if !grid[row][col] {
-				allClosed = false
-				break
-			}
-		}
-		if allClosed {
-			return true, nil
-		}
+	response += "\n🎉 Event has been marked as occurred!"
+	if len(r.Wins) > 0 {
+		response += "\n\n🏆 **BINGO!**\n" + formatWins(r.Wins, r.Stages)
 	}
 
-	// Check diagonal (top-left to bottom-right)
-	allClosed := true
-	for i := 0; i < gridSize; i++ {
-		if !grid[i][i] {
-			allClosed = false
-			break
-		}
-	}
-	if allClosed {
-		return true, nil
-	}
+	title := fmt.Sprintf("Event Closed: #%d — %s", r.Event.DisplayID, r.Event.Description)
+	return title, response, colorWin
+}
 
-	// Check diagonal (top-right to bottom-left)
-	allClosed = true
-	for i := 0; i < gridSize; i++ {
-		if !grid[i][gridSize-1-i] {
-			allClosed = false
-			break
-		}
+// voteProgress summarises an event's tally, including spectator votes when they count
+func voteProgress(c rules.Consensus, e rules.Eligibility, t rules.Tally) string {
+	progress := c.Progress(t)
+	if spectators := e.Progress(t); spectators != "" {
+		progress += ", " + spectators
 	}
-	if allClosed {
-		return true, nil
-	}
-
-	return false, nil
+	return progress
 }
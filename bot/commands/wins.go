@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fordtom/bingo/db"
+	"github.com/fordtom/bingo/rules"
+)
+
+// maxWinnerBoards caps how many boards one win announcement attaches
+const maxWinnerBoards = 4
+
+// formatWins renders wins as one line per stage and place, e.g. "🥇 1st — any line: @a, @b"
+func formatWins(wins []db.Win, stages []rules.Pattern) string {
+	var lines []string
+	for start := 0; start < len(wins); {
+		end := start
+		var mentions []string
+		for end < len(wins) && wins[end].Stage == wins[start].Stage && wins[end].Place == wins[start].Place {
+			mentions = append(mentions, fmt.Sprintf("<@%d>", wins[end].UserID))
+			end++
+		}
+
+		win := wins[start]
+		label := ""
+		if win.Stage >= 1 && win.Stage <= len(stages) {
+			label = stages[win.Stage-1].Describe()
+			if len(stages) > 1 {
+				label = fmt.Sprintf("stage %d (%s)", win.Stage, label)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s %s — %s: %s", placeMedal(win.Place), ordinal(win.Place), label, strings.Join(mentions, ", ")))
+		start = end
+	}
+	return strings.Join(lines, "\n")
+}
+
+// winnerBoards renders the boards behind newly recorded wins as image embeds
+// with their files, each drawn against the stage it won so the winning lines
+// are struck through. Nothing is attached while the game's boards are private.
+func winnerBoards(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB, gameID int64, wins []db.Win, stages []rules.Pattern) ([]*discordgo.MessageEmbed, []*discordgo.File) {
+	if len(wins) == 0 {
+		return nil, nil
+	}
+	game, err := database.GetGame(ctx, gameID)
+	if err != nil || game == nil || !boardsRevealed(game) {
+		return nil, nil
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	var files []*discordgo.File
+	shown := make(map[int64]bool)
+	for _, win := range wins {
+		if shown[win.UserID] || len(shown) == maxWinnerBoards || win.Stage < 1 || win.Stage > len(stages) {
+			continue
+		}
+		shown[win.UserID] = true
+
+		imageBytes, err := renderBoardPattern(ctx, database, game, win.UserID, stages[win.Stage-1])
+		if err != nil {
+			log.Printf("err %s actor=%s winning board for %d: %v", interactionLabel(i), interactionActor(i), win.UserID, err)
+			continue
+		}
+		filename := fmt.Sprintf("board_game%d_user%d.png", gameID, win.UserID)
+		embeds = append(embeds, &discordgo.MessageEmbed{
+			Title: fmt.Sprintf("%s %s's winning board", placeMedal(win.Place), userDisplayName(s, i.GuildID, fmt.Sprint(win.UserID))),
+			Color: colorWin,
+			Image: &discordgo.MessageEmbedImage{URL: "attachment://" + filename},
+		})
+		files = append(files, &discordgo.File{Name: filename, ContentType: "image/png", Reader: bytes.NewReader(imageBytes)})
+	}
+	return embeds, files
+}
+
+// describeStages renders a win progression for display, e.g. "any line → 2 lines → blackout"
+func describeStages(stages []rules.Pattern) string {
+	names := make([]string, len(stages))
+	for n, stage := range stages {
+		names[n] = stage.Describe()
+	}
+	return strings.Join(names, " → ")
+}
+
+// placeMedal returns a medal emoji for the top three places
+func placeMedal(place int) string {
+	switch place {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	}
+	return "🏅"
+}
+
+// ordinal formats a place as 1st, 2nd, 3rd, 4th...
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// purgeInterval is how often soft-deleted games are checked for purging
+const purgeInterval = time.Hour
+
+// startPurger purges games deleted more than retention ago, now and then every
+// purgeInterval, until the returned stop function is called
+func (b *Bot) startPurger(retention time.Duration) func() {
+	b.purgeDeletedGames(retention)
+
+	ticker := time.NewTicker(purgeInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				b.purgeDeletedGames(retention)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// purgeDeletedGames permanently removes games whose retention period has passed
+func (b *Bot) purgeDeletedGames(retention time.Duration) {
+	purged, err := b.db.PurgeDeletedGames(context.Background(), retention)
+	if err != nil {
+		log.Printf("Error purging deleted games: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d games deleted more than %s ago", purged, retention)
+	}
+}
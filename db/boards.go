@@ -5,6 +5,9 @@ import (
 	"database/sql"
 )
 
+// boardColumns lists the boards columns read into a Board, in field order
+const boardColumns = "board_id, game_id, user_id, grid_size, COALESCE(seed, 0), COALESCE(dealt_events, 0)"
+
 // CreateBoard creates a board for a user in a game
 func (db *DB) CreateBoard(ctx context.Context, tx *sql.Tx, gameID, userID int64, gridSize int) (int64, error) {
 	result, err := tx.ExecContext(ctx,
@@ -22,9 +25,9 @@ func (db *DB) GetUserBoard(ctx context.Context, gameID, userID int64) (*Board, [
 	// First get the board
 	var board Board
 	err := db.conn.QueryRowContext(ctx,
-		"SELECT board_id, game_id, user_id, grid_size FROM boards WHERE game_id = ? AND user_id = ?",
+		"SELECT "+boardColumns+" FROM boards WHERE game_id = ? AND user_id = ?",
 		gameID, userID,
-	).Scan(&board.ID, &board.GameID, &board.UserID, &board.GridSize)
+	).Scan(&board.ID, &board.GameID, &board.UserID, &board.GridSize, &board.Seed, &board.DealtEvents)
 	if err == sql.ErrNoRows {
 		return nil, nil, nil
 	}
@@ -34,9 +37,9 @@ func (db *DB) GetUserBoard(ctx context.Context, gameID, userID int64) (*Board, [
 
 	// Then get all squares with event details
 	rows, err := db.conn.QueryContext(ctx,
-		`SELECT bs.board_id, bs.row, bs.column, bs.event_id, e.description, e.status
+		`SELECT bs.board_id, bs.row, bs.column, bs.kind, COALESCE(bs.event_id, 0), COALESCE(e.description, 'FREE'), COALESCE(e.status, ''), e.closed_at
 		 FROM board_squares bs
-		 JOIN events e ON bs.event_id = e.event_id
+		 LEFT JOIN events e ON bs.event_id = e.event_id
 		 WHERE bs.board_id = ?
 		 ORDER BY bs.row, bs.column`,
 		board.ID,
@@ -50,8 +53,8 @@ func (db *DB) GetUserBoard(ctx context.Context, gameID, userID int64) (*Board, [
 	for rows.Next() {
 		var square BoardSquareWithEvent
 		if err := rows.Scan(
-			&square.BoardID, &square.Row, &square.Column, &square.EventID,
-			&square.EventDescription, &square.EventStatus,
+			&square.BoardID, &square.Row, &square.Column, &square.Kind, &square.EventID,
+			&square.EventDescription, &square.EventStatus, &square.EventClosedAt,
 		); err != nil {
 			return nil, nil, err
 		}
@@ -76,7 +79,7 @@ func (db *DB) CreateBoardSquare(ctx context.Context, tx *sql.Tx, boardID int64,
 // CreateBoardSquares bulk creates squares for a board
 func (db *DB) CreateBoardSquares(ctx context.Context, tx *sql.Tx, boardID int64, squares []BoardSquare) error {
 	stmt, err := tx.PrepareContext(ctx,
-		"INSERT INTO board_squares (board_id, row, column, event_id) VALUES (?, ?, ?, ?)",
+		"INSERT INTO board_squares (board_id, row, column, kind, event_id) VALUES (?, ?, ?, ?, ?)",
 	)
 	if err != nil {
 		return err
@@ -84,9 +87,65 @@ func (db *DB) CreateBoardSquares(ctx context.Context, tx *sql.Tx, boardID int64,
 	defer stmt.Close()
 
 	for _, square := range squares {
-		if _, err := stmt.ExecContext(ctx, boardID, square.Row, square.Column, square.EventID); err != nil {
+		kind, eventID := square.Kind, any(square.EventID)
+		if kind == "" {
+			kind = SquareKindEvent
+		}
+		if kind == SquareKindFree {
+			eventID = nil
+		}
+		if _, err := stmt.ExecContext(ctx, boardID, square.Row, square.Column, kind, eventID); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// GetGameBoards retrieves every board in a game, ordered by player
+func (db *DB) GetGameBoards(ctx context.Context, gameID int64) ([]Board, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT "+boardColumns+" FROM boards WHERE game_id = ? ORDER BY user_id",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var boards []Board
+	for rows.Next() {
+		var board Board
+		if err := rows.Scan(&board.ID, &board.GameID, &board.UserID, &board.GridSize, &board.Seed, &board.DealtEvents); err != nil {
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+	return boards, rows.Err()
+}
+
+// GetGameSquares retrieves every board square in a game, grouped by player
+func (db *DB) GetGameSquares(ctx context.Context, gameID int64) (map[int64][]BoardSquare, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT b.user_id, bs.board_id, bs.row, bs.column, bs.kind, COALESCE(bs.event_id, 0)
+		 FROM board_squares bs
+		 JOIN boards b ON b.board_id = bs.board_id
+		 WHERE b.game_id = ?
+		 ORDER BY b.user_id, bs.row, bs.column`,
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	squares := make(map[int64][]BoardSquare)
+	for rows.Next() {
+		var userID int64
+		var square BoardSquare
+		if err := rows.Scan(&userID, &square.BoardID, &square.Row, &square.Column, &square.Kind, &square.EventID); err != nil {
+			return nil, err
+		}
+		squares[userID] = append(squares[userID], square)
+	}
+	return squares, rows.Err()
+}
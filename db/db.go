@@ -7,6 +7,8 @@ import (
 	"os"
 	"time"
 
+	"github.com/fordtom/bingo/rules"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -18,20 +20,64 @@ type DB struct {
 	conn *sql.DB
 }
 
+// querier is satisfied by both *sql.DB and *sql.Tx, so read helpers can run inside or outside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 // EventStatus represents the status of an event
 type EventStatus string
 
 const (
-	EventStatusOpen   EventStatus = "OPEN"
-	EventStatusClosed EventStatus = "CLOSED"
+	EventStatusOpen    EventStatus = "OPEN"
+	EventStatusClosed  EventStatus = "CLOSED"
+	EventStatusRetired EventStatus = "RETIRED" // withdrawn by the host; no longer on any board
+)
+
+// SquareKind says what a board square holds
+type SquareKind string
+
+const (
+	SquareKindEvent SquareKind = "EVENT" // an event, marked once it closes
+	SquareKindFree  SquareKind = "FREE"  // no event; always marked
 )
 
+// DealMode says how events are dealt onto boards
+type DealMode string
+
+const (
+	DealStandard DealMode = "standard" // weighted random deal
+	DealFair     DealMode = "fair"     // even event usage, limited overlap and balanced difficulty
+)
+
+// DealModes lists every deal mode
+var DealModes = []DealMode{DealStandard, DealFair}
+
 // Domain types
 type Game struct {
-	ID       int64
-	Title    string
-	IsActive bool
-	GridSize int
+	ID            int64
+	Title         string
+	IsActive      bool
+	GridSize      int
+	GuildID       int64
+	ChannelID     int64
+	WinPattern    string      // stages separated by ">", see rules.ParseStages
+	PrizePlaces   int         // places awarded per stage
+	Consensus     string      // vote rule, see rules.ParseConsensus
+	HostID        int64       // user who created the game; 0 for games predating hosts
+	Eligibility   string      // who may vote, see rules.ParseEligibility
+	DeletedAt     *time.Time  // set while the game is soft-deleted
+	State         string      // lifecycle state, see GameState
+	FinishedAt    *time.Time  // when the game finished, if it has
+	FreeCell      *rules.Cell // free square on every board, if any
+	Seed          int64       // seeds the board dealing RNG
+	DealtEvents   int         // events (by display ID) the boards were dealt from; 0 for unseeded games
+	Dealing       string      // how boards are dealt, see DealMode
+	Lobby         bool        // players are still signing up; boards are dealt when it starts
+	PrivateBoards bool        // players only see their own board until the game finishes
+	DMBoards      bool        // boards are sent to players by DM when voting opens
 }
 
 type Event struct {
@@ -40,26 +86,39 @@ type Event struct {
 	DisplayID   int
 	Description string
 	Status      string
+	Category    string   // spreads events across each board; empty if uncategorised
+	Weight      float64  // relative number of boards the event lands on; 0 means 1
+	Tags        []string // free-form labels
+	Free        bool     // pre-marked on every board; created CLOSED
 }
 
 type Board struct {
-	ID       int64
-	GameID   int64
-	UserID   int64
-	GridSize int
+	ID          int64
+	GameID      int64
+	UserID      int64
+	GridSize    int
+	Seed        int64 // seeds a late joiner's deal; 0 for boards dealt with the game
+	DealtEvents int   // events (by display ID) a late joiner's board was dealt from
 }
 
 type BoardSquare struct {
 	BoardID int64
 	Row     int
 	Column  int
-	EventID int64
+	Kind    SquareKind // empty means SquareKindEvent
+	EventID int64      // 0 for free squares
 }
 
 type BoardSquareWithEvent struct {
 	BoardSquare
 	EventDescription string
 	EventStatus      string
+	EventClosedAt    *time.Time // when the square's event closed, if known
+}
+
+// Marked reports whether the square counts toward winning patterns
+func (sq BoardSquareWithEvent) Marked() bool {
+	return sq.Kind == SquareKindFree || sq.EventStatus == string(EventStatusClosed)
 }
 
 type Vote struct {
@@ -68,6 +127,16 @@ type Vote struct {
 	VotedAt time.Time
 }
 
+type Win struct {
+	ID      int64
+	GameID  int64
+	UserID  int64
+	Stage   int // 1-based index into the game's win pattern stages
+	Place   int
+	EventID int64 // the event whose closing completed the pattern
+	WonAt   time.Time
+}
+
 // InitDB creates and configures the database connection
 func InitDB() (*DB, error) {
 	path := os.Getenv("DB_PATH")
@@ -91,32 +160,15 @@ func InitDB() (*DB, error) {
 		return nil, err
 	}
 
-	// Ensure schema exists
-	if err := ensureSchema(conn); err != nil {
+	// Bring the schema up to date
+	if err := migrate(context.Background(), conn); err != nil {
+		conn.Close()
 		return nil, err
 	}
 
 	return &DB{conn: conn}, nil
 }
 
-// ensureSchema creates tables if they don't exist
-func ensureSchema(conn *sql.DB) error {
-	// Check if tables already exist by looking for the games table
-	var count int
-	err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='games'").Scan(&count)
-	if err != nil {
-		return err
-	}
-
-	// If tables don't exist, create them
-	if count == 0 {
-		_, err := conn.Exec(initSchemaSQL)
-		return err
-	}
-
-	return nil
-}
-
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.conn.Close()
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/fordtom/bingo/rules"
+)
+
+// openTestDB opens a freshly migrated database that is closed when the test ends
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "bingo.db"))
+	database, err := InitDB()
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// createTestGame stores game with events numbered 1..events and deals each
+// player an identical board, its squares holding events 1, 2, ... in row order.
+// Unset rules fall back to the defaults and the host is user 1.
+func createTestGame(t *testing.T, database *DB, game Game, events int, players ...int64) *Game {
+	t.Helper()
+	ctx := context.Background()
+	if game.GridSize == 0 {
+		game.GridSize = 1
+	}
+	if game.WinPattern == "" {
+		game.WinPattern = rules.DefaultPattern
+	}
+	if game.PrizePlaces == 0 {
+		game.PrizePlaces = 1
+	}
+	if game.Consensus == "" {
+		game.Consensus = rules.DefaultConsensus
+	}
+	if game.Eligibility == "" {
+		game.Eligibility = rules.DefaultEligibility
+	}
+	if game.HostID == 0 {
+		game.HostID = 1
+	}
+	game.DealtEvents = events
+
+	id, err := database.CreateGame(ctx, game)
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	err = database.WithTx(ctx, func(tx *sql.Tx) error {
+		eventIDs := make([]int64, events)
+		for n := range eventIDs {
+			if eventIDs[n], err = database.CreateEvent(ctx, tx, Event{GameID: id, DisplayID: n + 1, Description: fmt.Sprintf("event %d", n+1)}); err != nil {
+				return err
+			}
+		}
+		for _, userID := range players {
+			boardID, err := database.CreateBoard(ctx, tx, id, userID, game.GridSize)
+			if err != nil {
+				return err
+			}
+			var squares []BoardSquare
+			for n := range game.GridSize * game.GridSize {
+				squares = append(squares, BoardSquare{Row: n / game.GridSize, Column: n % game.GridSize, EventID: eventIDs[n]})
+			}
+			if err := database.CreateBoardSquares(ctx, tx, boardID, squares); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("creating test game: %v", err)
+	}
+
+	stored, err := database.GetGame(ctx, id)
+	if err != nil {
+		t.Fatalf("GetGame: %v", err)
+	}
+	return stored
+}
@@ -0,0 +1,54 @@
+package db
+
+import "context"
+
+// EventPanel is a posted voting panel that is kept up to date as votes come in
+type EventPanel struct {
+	MessageID int64
+	ChannelID int64
+	GameID    int64
+	Page      int // the page of open events the panel shows
+}
+
+// TrackPanel records a panel message so it is refreshed as the game changes
+func (db *DB) TrackPanel(ctx context.Context, panel EventPanel) error {
+	_, err := db.conn.ExecContext(ctx,
+		"INSERT OR REPLACE INTO event_panels (message_id, channel_id, game_id, page) VALUES (?, ?, ?, ?)",
+		panel.MessageID, panel.ChannelID, panel.GameID, panel.Page,
+	)
+	return err
+}
+
+// SetPanelPage remembers which page a tracked panel shows
+func (db *DB) SetPanelPage(ctx context.Context, messageID int64, page int) error {
+	_, err := db.conn.ExecContext(ctx, "UPDATE event_panels SET page = ? WHERE message_id = ?", page, messageID)
+	return err
+}
+
+// UntrackPanel stops refreshing a panel message, e.g. once it has been deleted
+func (db *DB) UntrackPanel(ctx context.Context, messageID int64) error {
+	_, err := db.conn.ExecContext(ctx, "DELETE FROM event_panels WHERE message_id = ?", messageID)
+	return err
+}
+
+// GetEventPanels returns a game's tracked panel messages, oldest first
+func (db *DB) GetEventPanels(ctx context.Context, gameID int64) ([]EventPanel, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT message_id, channel_id, game_id, page FROM event_panels WHERE game_id = ? ORDER BY created_at, message_id",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var panels []EventPanel
+	for rows.Next() {
+		var panel EventPanel
+		if err := rows.Scan(&panel.MessageID, &panel.ChannelID, &panel.GameID, &panel.Page); err != nil {
+			return nil, err
+		}
+		panels = append(panels, panel)
+	}
+	return panels, rows.Err()
+}
@@ -3,13 +3,45 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fordtom/bingo/rules"
 )
 
-// CreateEvent creates a single event for a game with a specific display_id
-func (db *DB) CreateEvent(ctx context.Context, tx *sql.Tx, gameID int64, displayID int, description string) (int64, error) {
+// eventColumns lists the events columns read by scanEvent, in order
+const eventColumns = "event_id, game_id, display_id, description, status, category, weight, tags, is_free"
+
+// scanEvent reads a row selected with eventColumns
+func scanEvent(row rowScanner) (*Event, error) {
+	var event Event
+	var tags string
+	if err := row.Scan(&event.ID, &event.GameID, &event.DisplayID, &event.Description, &event.Status, &event.Category, &event.Weight, &tags, &event.Free); err != nil {
+		return nil, err
+	}
+	if tags != "" {
+		event.Tags = strings.Split(tags, ",")
+	}
+	return &event, nil
+}
+
+// CreateEvent creates an event for a game and returns its ID. The event's ID is
+// ignored; free events are always created CLOSED and others default to OPEN.
+func (db *DB) CreateEvent(ctx context.Context, tx *sql.Tx, event Event) (int64, error) {
+	status := EventStatus(event.Status)
+	if status == "" {
+		status = EventStatusOpen
+	}
+	if event.Free {
+		status = EventStatusClosed
+	}
+	if event.Weight <= 0 {
+		event.Weight = 1
+	}
 	result, err := tx.ExecContext(ctx,
-		"INSERT INTO events (game_id, display_id, description, status) VALUES (?, ?, ?, ?)",
-		gameID, displayID, description, EventStatusOpen,
+		"INSERT INTO events (game_id, display_id, description, status, category, weight, tags, is_free) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		event.GameID, event.DisplayID, event.Description, status, event.Category, event.Weight, strings.Join(event.Tags, ","), event.Free,
 	)
 	if err != nil {
 		return 0, err
@@ -38,24 +70,20 @@ func (db *DB) CreateEvents(ctx context.Context, tx *sql.Tx, gameID int64, descri
 
 // GetEventByDisplayID retrieves an event by its user-facing display_id within a game
 func (db *DB) GetEventByDisplayID(ctx context.Context, gameID int64, displayID int) (*Event, error) {
-	var event Event
-	err := db.conn.QueryRowContext(ctx,
-		"SELECT event_id, game_id, display_id, description, status FROM events WHERE game_id = ? AND display_id = ?",
+	event, err := scanEvent(db.conn.QueryRowContext(ctx,
+		"SELECT "+eventColumns+" FROM events WHERE game_id = ? AND display_id = ?",
 		gameID, displayID,
-	).Scan(&event.ID, &event.GameID, &event.DisplayID, &event.Description, &event.Status)
+	))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
-	}
-	return &event, nil
+	return event, err
 }
 
 // GetGameEvents retrieves all events for a game, ordered by display_id
 func (db *DB) GetGameEvents(ctx context.Context, gameID int64) ([]Event, error) {
 	rows, err := db.conn.QueryContext(ctx,
-		"SELECT event_id, game_id, display_id, description, status FROM events WHERE game_id = ? ORDER BY display_id",
+		"SELECT "+eventColumns+" FROM events WHERE game_id = ? ORDER BY display_id",
 		gameID,
 	)
 	if err != nil {
@@ -65,20 +93,20 @@ func (db *DB) GetGameEvents(ctx context.Context, gameID int64) ([]Event, error)
 
 	var events []Event
 	for rows.Next() {
-		var event Event
-		if err := rows.Scan(&event.ID, &event.GameID, &event.DisplayID, &event.Description, &event.Status); err != nil {
+		event, err := scanEvent(rows)
+		if err != nil {
 			return nil, err
 		}
-		events = append(events, event)
+		events = append(events, *event)
 	}
 	return events, rows.Err()
 }
 
-// UpdateEventStatus changes an event's status
+// UpdateEventStatus changes an event's status, noting when it closed
 func (db *DB) UpdateEventStatus(ctx context.Context, eventID int64, status EventStatus) error {
 	_, err := db.conn.ExecContext(ctx,
-		"UPDATE events SET status = ? WHERE event_id = ?",
-		status, eventID,
+		"UPDATE events SET status = ?, closed_at = CASE WHEN ? THEN CURRENT_TIMESTAMP END WHERE event_id = ?",
+		status, status == EventStatusClosed, eventID,
 	)
 	return err
 }
@@ -105,3 +133,294 @@ func (db *DB) GetEventDisplayIDMap(ctx context.Context, gameID int64) (map[int64
 	}
 	return displayMap, rows.Err()
 }
+
+// Errors returned by ReopenEvent
+var (
+	// ErrEventNotClosed means the event isn't a closed, non-free event of the game
+	ErrEventNotClosed = errors.New("event not closed")
+	// ErrVotesStillReached means the votes it would keep already close the
+	// event, so nothing could close it again
+	ErrVotesStillReached = errors.New("kept votes still reach consensus")
+)
+
+// ReopenEvent sets a closed event back to OPEN, optionally clearing its votes,
+// and revokes any wins that no longer hold without it. The revoked wins are
+// returned. Only a closed, non-free event can be reopened, or ErrEventNotClosed
+// is returned. Kept votes must fall short of the game's consensus rule and
+// spectator quorum, or ErrVotesStillReached is returned.
+func (db *DB) ReopenEvent(ctx context.Context, gameID, eventID int64, clearVotes bool) ([]Win, error) {
+	var revoked []Win
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		reopened, err := tx.ExecContext(ctx,
+			"UPDATE events SET status = ?, closed_at = NULL WHERE event_id = ? AND game_id = ? AND status = ? AND is_free = 0",
+			EventStatusOpen, eventID, gameID, EventStatusClosed,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := reopened.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrEventNotClosed
+		}
+
+		if clearVotes {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM votes WHERE event_id = ?", eventID); err != nil {
+				return err
+			}
+		} else {
+			game, err := getGame(ctx, tx, gameID)
+			if err != nil {
+				return err
+			}
+			if game == nil {
+				return fmt.Errorf("game %d not found", gameID)
+			}
+			consensus, err := rules.ParseConsensus(game.Consensus)
+			if err != nil {
+				return err
+			}
+			eligibility, err := rules.ParseEligibility(game.Eligibility)
+			if err != nil {
+				return err
+			}
+			tally, err := eventTally(ctx, tx, game, eventID)
+			if err != nil {
+				return err
+			}
+			if consensus.Reached(tally) || eligibility.QuorumReached(tally) {
+				return ErrVotesStillReached
+			}
+		}
+
+		revoked, err = revokeInvalidWins(ctx, tx, gameID)
+		return err
+	})
+	return revoked, err
+}
+
+// UpdateEventDescription changes an event's description
+func (db *DB) UpdateEventDescription(ctx context.Context, eventID int64, description string) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE events SET description = ? WHERE event_id = ?",
+		description, eventID,
+	)
+	return err
+}
+
+// AddEvent appends an open event to a game, numbered after its existing events
+func (db *DB) AddEvent(ctx context.Context, gameID int64, description string) (*Event, error) {
+	event := &Event{GameID: gameID, Description: description, Status: string(EventStatusOpen), Weight: 1}
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx,
+			"SELECT COALESCE(MAX(display_id), 0) + 1 FROM events WHERE game_id = ?",
+			gameID,
+		).Scan(&event.DisplayID); err != nil {
+			return err
+		}
+		var err error
+		event.ID, err = db.CreateEvent(ctx, tx, *event)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// RetireMode says what happens to board squares showing a retired event
+type RetireMode string
+
+const (
+	RetireReplace RetireMode = "replace" // swap in another open event, or free the square if none fits
+	RetireFree    RetireMode = "free"    // turn the square into a free, always-marked square
+)
+
+// ErrFreeEvent is returned by RetireEvent for a free event, which stays marked on every board
+var ErrFreeEvent = errors.New("free events can't be retired")
+
+// RetireResult describes the board changes made by RetireEvent
+type RetireResult struct {
+	Replaced int   // squares given a replacement event
+	Freed    int   // squares turned into free squares
+	Revoked  []Win // wins that no longer hold
+	Wins     []Win // wins newly completed, e.g. by freed squares
+}
+
+// RetireEvent withdraws an event from a game: its votes are discarded and every
+// board square showing it is replaced or freed according to mode. With
+// RetireReplace, replacementID (if non-zero) is tried first on each board;
+// otherwise the open event on the fewest boards is used. Wins are then
+// rechecked, and new ones are only recorded while the game is running. Free
+// events can't be retired. ErrEventNotFound is returned if the event isn't
+// one of the game's.
+func (db *DB) RetireEvent(ctx context.Context, gameID, eventID int64, mode RetireMode, replacementID int64) (*RetireResult, error) {
+	result := &RetireResult{}
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		retired, err := tx.ExecContext(ctx,
+			"UPDATE events SET status = ?, retire_mode = ? WHERE event_id = ? AND game_id = ? AND status != ? AND is_free = 0",
+			EventStatusRetired, mode, eventID, gameID, EventStatusRetired,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := retired.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			var free bool
+			err := tx.QueryRowContext(ctx,
+				"SELECT is_free FROM events WHERE event_id = ? AND game_id = ?",
+				eventID, gameID,
+			).Scan(&free)
+			switch {
+			case err == sql.ErrNoRows:
+				return ErrEventNotFound
+			case err != nil:
+				return err
+			case free:
+				return ErrFreeEvent
+			}
+			return ErrEventRetired
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM votes WHERE event_id = ?", eventID); err != nil {
+			return err
+		}
+
+		if mode == RetireReplace {
+			if result.Replaced, err = replaceSquares(ctx, tx, gameID, eventID, replacementID); err != nil {
+				return err
+			}
+		}
+		freed, err := tx.ExecContext(ctx,
+			"UPDATE board_squares SET kind = ?, event_id = NULL WHERE event_id = ?",
+			SquareKindFree, eventID,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := freed.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result.Freed = int(n)
+
+		if result.Revoked, err = revokeInvalidWins(ctx, tx, gameID); err != nil {
+			return err
+		}
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if game != nil && GameState(game.State) == GameStateRunning {
+			result.Wins, err = recordWins(ctx, tx, gameID, eventID)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// replaceSquares points each square showing eventID at a different event not
+// already on that board, spreading replacements across the least-used open
+// events. replacementID is only used if it is one of those open events. Squares
+// with no possible replacement are left for the caller to free.
+func replaceSquares(ctx context.Context, tx *sql.Tx, gameID, eventID, replacementID int64) (int, error) {
+	// How many boards each candidate event already appears on
+	rows, err := tx.QueryContext(ctx,
+		`SELECT e.event_id, COUNT(bs.board_id)
+		 FROM events e
+		 LEFT JOIN board_squares bs ON bs.event_id = e.event_id
+		 WHERE e.game_id = ? AND e.status = ? AND e.event_id != ?
+		 GROUP BY e.event_id
+		 ORDER BY e.display_id`,
+		gameID, EventStatusOpen, eventID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var candidates []int64
+	usage := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		candidates = append(candidates, id)
+		usage[id] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// The boards showing the retired event, with the events already on each
+	rows, err = tx.QueryContext(ctx,
+		`SELECT bs.board_id, bs.row, bs.column, other.event_id
+		 FROM board_squares bs
+		 JOIN board_squares other ON other.board_id = bs.board_id AND other.event_id IS NOT NULL
+		 WHERE bs.event_id = ?
+		 ORDER BY bs.board_id`,
+		eventID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	type square struct {
+		boardID  int64
+		row, col int
+	}
+	var squares []square
+	onBoard := make(map[int64]map[int64]bool)
+	for rows.Next() {
+		var sq square
+		var otherID int64
+		if err := rows.Scan(&sq.boardID, &sq.row, &sq.col, &otherID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if onBoard[sq.boardID] == nil {
+			onBoard[sq.boardID] = make(map[int64]bool)
+			squares = append(squares, sq)
+		}
+		onBoard[sq.boardID][otherID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// A closed replacement would mark squares no vote has earned
+	if _, open := usage[replacementID]; !open {
+		replacementID = 0
+	}
+
+	replaced := 0
+	for _, sq := range squares {
+		choice := int64(0)
+		if replacementID != 0 && !onBoard[sq.boardID][replacementID] {
+			choice = replacementID
+		} else {
+			for _, id := range candidates {
+				if !onBoard[sq.boardID][id] && (choice == 0 || usage[id] < usage[choice]) {
+					choice = id
+				}
+			}
+		}
+		if choice == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE board_squares SET event_id = ? WHERE board_id = ? AND row = ? AND column = ?",
+			choice, sq.boardID, sq.row, sq.col,
+		); err != nil {
+			return 0, err
+		}
+		usage[choice]++
+		replaced++
+	}
+	return replaced, nil
+}
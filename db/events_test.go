@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestReopenEventOnlyClosed(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+	game := createTestGame(t, database, Game{Consensus: "fixed:1"}, 3, 10, 11)
+	other := createTestGame(t, database, Game{Consensus: "fixed:1"}, 1, 10)
+
+	events, err := database.GetGameEvents(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetGameEvents: %v", err)
+	}
+	open, closed, retired := events[0], events[1], events[2]
+	if _, err := database.CastVote(ctx, game.ID, closed.DisplayID, 10); err != nil {
+		t.Fatalf("CastVote: %v", err)
+	}
+	if _, err := database.RetireEvent(ctx, game.ID, retired.ID, RetireFree, 0); err != nil {
+		t.Fatalf("RetireEvent: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		gameID  int64
+		eventID int64
+		want    error
+	}{
+		{"open", game.ID, open.ID, ErrEventNotClosed},
+		{"retired", game.ID, retired.ID, ErrEventNotClosed},
+		{"other game", other.ID, closed.ID, ErrEventNotClosed},
+		{"closed", game.ID, closed.ID, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := database.ReopenEvent(ctx, tt.gameID, tt.eventID, true); !errors.Is(err, tt.want) {
+				t.Errorf("ReopenEvent = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	event, err := database.GetEventByDisplayID(ctx, game.ID, retired.DisplayID)
+	if err != nil || event.Status != string(EventStatusRetired) {
+		t.Errorf("retired event after reopen attempt = %+v, %v; want it still retired", event, err)
+	}
+}
+
+func TestRetireEventErrors(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+	game := createTestGame(t, database, Game{}, 2, 10)
+	other := createTestGame(t, database, Game{}, 1, 10)
+
+	events, err := database.GetGameEvents(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetGameEvents: %v", err)
+	}
+	free, err := database.AddEvent(ctx, game.ID, "free space")
+	if err != nil {
+		t.Fatalf("AddEvent: %v", err)
+	}
+	if err := database.WithTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE events SET is_free = 1, status = ? WHERE event_id = ?", EventStatusClosed, free.ID)
+		return err
+	}); err != nil {
+		t.Fatalf("marking event free: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		gameID  int64
+		eventID int64
+		want    error
+	}{
+		{"missing event", game.ID, 9999, ErrEventNotFound},
+		{"other game's event", other.ID, events[1].ID, ErrEventNotFound},
+		{"free event", game.ID, free.ID, ErrFreeEvent},
+		{"retire", game.ID, events[1].ID, nil},
+		{"retire twice", game.ID, events[1].ID, ErrEventRetired},
+	}
+	for _, tt := range tests {
+		if _, err := database.RetireEvent(ctx, tt.gameID, tt.eventID, RetireReplace, 0); !errors.Is(err, tt.want) {
+			t.Errorf("%s: RetireEvent = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
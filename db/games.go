@@ -3,13 +3,57 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fordtom/bingo/rules"
 )
 
-// CreateGame creates a new game and returns its ID
-func (db *DB) CreateGame(ctx context.Context, title string, gridSize int) (int64, error) {
+// gameColumns lists the games columns read by scanGame, in order
+const gameColumns = "game_id, title, is_active, grid_size, COALESCE(guild_id, 0), COALESCE(channel_id, 0), win_pattern, prize_places, consensus, COALESCE(host_id, 0), eligibility, deleted_at, state, finished_at, free_row, free_col, COALESCE(seed, 0), COALESCE(dealt_events, 0), dealing, lobby, private_boards, dm_boards"
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanGame reads a row selected with gameColumns
+func scanGame(row rowScanner) (*Game, error) {
+	var game Game
+	var deletedAt, finishedAt sql.NullTime
+	var freeRow, freeCol sql.NullInt64
+	if err := row.Scan(&game.ID, &game.Title, &game.IsActive, &game.GridSize, &game.GuildID, &game.ChannelID, &game.WinPattern, &game.PrizePlaces, &game.Consensus, &game.HostID, &game.Eligibility, &deletedAt, &game.State, &finishedAt, &freeRow, &freeCol, &game.Seed, &game.DealtEvents, &game.Dealing, &game.Lobby, &game.PrivateBoards, &game.DMBoards); err != nil {
+		return nil, err
+	}
+	if freeRow.Valid && freeCol.Valid {
+		game.FreeCell = &rules.Cell{Row: int(freeRow.Int64), Col: int(freeCol.Int64)}
+	}
+	if deletedAt.Valid {
+		game.DeletedAt = &deletedAt.Time
+	}
+	if finishedAt.Valid {
+		game.FinishedAt = &finishedAt.Time
+	}
+	return &game, nil
+}
+
+// CreateGame creates a new game from the given settings and returns its ID.
+// The game's ID, IsActive and timestamp fields are ignored; an empty State means running
+// and an empty Dealing means standard.
+func (db *DB) CreateGame(ctx context.Context, game Game) (int64, error) {
+	if game.State == "" {
+		game.State = string(GameStateRunning)
+	}
+	if game.Dealing == "" {
+		game.Dealing = string(DealStandard)
+	}
+	var freeRow, freeCol any
+	if game.FreeCell != nil {
+		freeRow, freeCol = game.FreeCell.Row, game.FreeCell.Col
+	}
 	result, err := db.conn.ExecContext(ctx,
-		"INSERT INTO games (title, grid_size) VALUES (?, ?)",
-		title, gridSize,
+		"INSERT INTO games (guild_id, channel_id, title, grid_size, win_pattern, prize_places, consensus, host_id, eligibility, state, free_row, free_col, seed, dealt_events, dealing, lobby, private_boards, dm_boards) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		game.GuildID, game.ChannelID, game.Title, game.GridSize, game.WinPattern, game.PrizePlaces, game.Consensus, game.HostID, game.Eligibility, game.State, freeRow, freeCol, game.Seed, game.DealtEvents, game.Dealing, game.Lobby, game.PrivateBoards, game.DMBoards,
 	)
 	if err != nil {
 		return 0, err
@@ -17,41 +61,50 @@ func (db *DB) CreateGame(ctx context.Context, title string, gridSize int) (int64
 	return result.LastInsertId()
 }
 
-// GetGame retrieves a specific game by ID
+// GetGame retrieves a specific game by ID, including soft-deleted games
 func (db *DB) GetGame(ctx context.Context, gameID int64) (*Game, error) {
-	var game Game
-	err := db.conn.QueryRowContext(ctx,
-		"SELECT game_id, title, is_active, grid_size FROM games WHERE game_id = ?",
+	return getGame(ctx, db.conn, gameID)
+}
+
+// getGame retrieves a game by ID using q, which may be a transaction
+func getGame(ctx context.Context, q querier, gameID int64) (*Game, error) {
+	game, err := scanGame(q.QueryRowContext(ctx,
+		"SELECT "+gameColumns+" FROM games WHERE game_id = ?",
 		gameID,
-	).Scan(&game.ID, &game.Title, &game.IsActive, &game.GridSize)
+	))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
-	}
-	return &game, nil
+	return game, err
 }
 
-// GetActiveGame retrieves the currently active game (returns nil if none)
-func (db *DB) GetActiveGame(ctx context.Context) (*Game, error) {
-	var game Game
-	err := db.conn.QueryRowContext(ctx,
-		"SELECT game_id, title, is_active, grid_size FROM games WHERE is_active = 1",
-	).Scan(&game.ID, &game.Title, &game.IsActive, &game.GridSize)
+// GetActiveGame retrieves the active game for a channel (returns nil if none)
+func (db *DB) GetActiveGame(ctx context.Context, channelID int64) (*Game, error) {
+	game, err := scanGame(db.conn.QueryRowContext(ctx,
+		"SELECT "+gameColumns+" FROM games WHERE channel_id = ? AND is_active = 1",
+		channelID,
+	))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
-	}
-	return &game, nil
+	return game, err
 }
 
-// ListGames retrieves all games
-func (db *DB) ListGames(ctx context.Context) ([]Game, error) {
-	rows, err := db.conn.QueryContext(ctx,
-		"SELECT game_id, title, is_active, grid_size FROM games ORDER BY game_id DESC",
+// ListGames retrieves all games in a channel, leaving out archived and soft-deleted ones
+func (db *DB) ListGames(ctx context.Context, channelID int64) ([]Game, error) {
+	return listGames(ctx, db.conn, "channel_id = ? AND deleted_at IS NULL AND state != ?", channelID, GameStateArchived)
+}
+
+// ListDeletedGames retrieves a channel's soft-deleted games, most recently deleted first
+func (db *DB) ListDeletedGames(ctx context.Context, channelID int64) ([]Game, error) {
+	return listGames(ctx, db.conn, "channel_id = ? AND deleted_at IS NOT NULL", channelID)
+}
+
+// listGames retrieves the games matching where, newest first
+func listGames(ctx context.Context, q querier, where string, args ...any) ([]Game, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT "+gameColumns+" FROM games WHERE "+where+" ORDER BY COALESCE(deleted_at, 0) DESC, game_id DESC",
+		args...,
 	)
 	if err != nil {
 		return nil, err
@@ -60,20 +113,23 @@ func (db *DB) ListGames(ctx context.Context) ([]Game, error) {
 
 	var games []Game
 	for rows.Next() {
-		var game Game
-		if err := rows.Scan(&game.ID, &game.Title, &game.IsActive, &game.GridSize); err != nil {
+		game, err := scanGame(rows)
+		if err != nil {
 			return nil, err
 		}
-		games = append(games, game)
+		games = append(games, *game)
 	}
 	return games, rows.Err()
 }
 
-// SetActiveGame sets a game as active and unsets all others
+// SetActiveGame sets a game as active and unsets all others in the same channel
 func (db *DB) SetActiveGame(ctx context.Context, gameID int64) error {
 	return db.WithTx(ctx, func(tx *sql.Tx) error {
-		// Unset all active games
-		if _, err := tx.ExecContext(ctx, "UPDATE games SET is_active = 0 WHERE is_active = 1"); err != nil {
+		// Unset the channel's active game
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE games SET is_active = 0 WHERE is_active = 1 AND channel_id = (SELECT channel_id FROM games WHERE game_id = ?)",
+			gameID,
+		); err != nil {
 			return err
 		}
 		// Set the target game as active
@@ -84,33 +140,204 @@ func (db *DB) SetActiveGame(ctx context.Context, gameID int64) error {
 	})
 }
 
+// SetGameConsensus changes a game's vote consensus rule. While the game is
+// running, open events whose votes already satisfy the new rule are closed and
+// their wins recorded in the same transaction, and returned.
+func (db *DB) SetGameConsensus(ctx context.Context, gameID int64, consensus string) ([]Event, []Win, error) {
+	var closed []Event
+	var wins []Win
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE games SET consensus = ? WHERE game_id = ?",
+			consensus, gameID,
+		); err != nil {
+			return err
+		}
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if game != nil && GameState(game.State) == GameStateRunning {
+			closed, wins, err = closeReachedEvents(ctx, tx, game)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return closed, wins, nil
+}
+
+// SetGameEligibility changes who may vote on a game's events
+func (db *DB) SetGameEligibility(ctx context.Context, gameID int64, eligibility string) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE games SET eligibility = ? WHERE game_id = ?",
+		eligibility, gameID,
+	)
+	return err
+}
+
+// SetGameReferees replaces a game's referees
+func (db *DB) SetGameReferees(ctx context.Context, gameID int64, userIDs []int64) error {
+	return db.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM game_referees WHERE game_id = ?", gameID); err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT OR IGNORE INTO game_referees (game_id, user_id) VALUES (?, ?)",
+				gameID, userID,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetGameReferees returns the user IDs of a game's referees
+func (db *DB) GetGameReferees(ctx context.Context, gameID int64) ([]int64, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT user_id FROM game_referees WHERE game_id = ? ORDER BY user_id",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var referees []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		referees = append(referees, userID)
+	}
+	return referees, rows.Err()
+}
+
+// ClaimUnscopedGames assigns games created before channel scoping to a guild channel
+func (db *DB) ClaimUnscopedGames(ctx context.Context, guildID, channelID int64) (int64, error) {
+	result, err := db.conn.ExecContext(ctx,
+		"UPDATE games SET guild_id = ?, channel_id = ? WHERE channel_id IS NULL",
+		guildID, channelID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // DeleteGame removes a game
 func (db *DB) DeleteGame(ctx context.Context, gameID int64) error {
 	_, err := db.conn.ExecContext(ctx, "DELETE FROM games WHERE game_id = ?", gameID)
 	return err
 }
 
-// DeleteGameCascade removes a game and all associated data in proper order
-func (db *DB) DeleteGameCascade(ctx context.Context, gameID int64) error {
-	return db.WithTx(ctx, func(tx *sql.Tx) error {
-		// Delete boards (cascades to board_squares via FK)
-		if _, err := tx.ExecContext(ctx, "DELETE FROM boards WHERE game_id = ?", gameID); err != nil {
+// SoftDeleteGame hides a game until it is restored or purged. A deleted game is never active.
+func (db *DB) SoftDeleteGame(ctx context.Context, gameID int64) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE games SET deleted_at = CURRENT_TIMESTAMP, is_active = 0 WHERE game_id = ? AND deleted_at IS NULL",
+		gameID,
+	)
+	return err
+}
+
+// RestoreGame undoes SoftDeleteGame
+func (db *DB) RestoreGame(ctx context.Context, gameID int64) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE games SET deleted_at = NULL WHERE game_id = ?",
+		gameID,
+	)
+	return err
+}
+
+// PurgeDeletedGames permanently removes games soft-deleted more than retention ago,
+// returning how many were purged
+func (db *DB) PurgeDeletedGames(ctx context.Context, retention time.Duration) (int, error) {
+	var purged int
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT game_id FROM games WHERE deleted_at IS NOT NULL AND deleted_at <= datetime('now', ?)",
+			fmt.Sprintf("-%d seconds", int64(retention.Seconds())),
+		)
+		if err != nil {
 			return err
 		}
-		// Delete votes for events in this game
-		if _, err := tx.ExecContext(ctx, "DELETE FROM votes WHERE event_id IN (SELECT event_id FROM events WHERE game_id = ?)", gameID); err != nil {
-			return err
+		var gameIDs []int64
+		for rows.Next() {
+			var gameID int64
+			if err := rows.Scan(&gameID); err != nil {
+				rows.Close()
+				return err
+			}
+			gameIDs = append(gameIDs, gameID)
 		}
-		// Delete events
-		if _, err := tx.ExecContext(ctx, "DELETE FROM events WHERE game_id = ?", gameID); err != nil {
+		rows.Close()
+		if err := rows.Err(); err != nil {
 			return err
 		}
-		// Delete game
-		if _, err := tx.ExecContext(ctx, "DELETE FROM games WHERE game_id = ?", gameID); err != nil {
-			return err
+
+		for _, gameID := range gameIDs {
+			if err := deleteGameCascade(ctx, tx, gameID); err != nil {
+				return err
+			}
 		}
+		purged = len(gameIDs)
 		return nil
 	})
+	return purged, err
+}
+
+// DeleteGameCascade removes a game and all associated data in proper order
+func (db *DB) DeleteGameCascade(ctx context.Context, gameID int64) error {
+	return db.WithTx(ctx, func(tx *sql.Tx) error {
+		return deleteGameCascade(ctx, tx, gameID)
+	})
+}
+
+// deleteGameCascade implements DeleteGameCascade inside the caller's transaction
+func deleteGameCascade(ctx context.Context, tx *sql.Tx, gameID int64) error {
+	// Delete boards (cascades to board_squares via FK)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM boards WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	// Delete votes for events in this game
+	if _, err := tx.ExecContext(ctx, "DELETE FROM votes WHERE event_id IN (SELECT event_id FROM events WHERE game_id = ?)", gameID); err != nil {
+		return err
+	}
+	// Delete wins, which reference both the game and its events
+	if _, err := tx.ExecContext(ctx, "DELETE FROM wins WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	// Delete referees
+	if _, err := tx.ExecContext(ctx, "DELETE FROM game_referees WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	// Delete the record of removed players and any unstarted lobby
+	if _, err := tx.ExecContext(ctx, "DELETE FROM removed_players WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM lobby_players WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	// Stop tracking board and panel messages
+	if _, err := tx.ExecContext(ctx, "DELETE FROM live_boards WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM event_panels WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	// Delete events
+	if _, err := tx.ExecContext(ctx, "DELETE FROM events WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	// Delete game
+	if _, err := tx.ExecContext(ctx, "DELETE FROM games WHERE game_id = ?", gameID); err != nil {
+		return err
+	}
+	return nil
 }
 
 // GetPlayerCountForGame returns the number of players (boards) for a game
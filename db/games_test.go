@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetGameConsensusClosesReached(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+	game := createTestGame(t, database, Game{}, 1, 10, 11, 12, 13)
+
+	// Two of four players is short of the default 60%
+	for _, userID := range []int64{10, 11} {
+		if result, err := database.CastVote(ctx, game.ID, 1, userID); err != nil || result.Closed {
+			t.Fatalf("CastVote by %d = %+v, %v; want an open event", userID, result, err)
+		}
+	}
+
+	closed, wins, err := database.SetGameConsensus(ctx, game.ID, "fixed:3")
+	if err != nil || len(closed) != 0 {
+		t.Fatalf("raising the rule = %v, %v; want nothing closed", closed, err)
+	}
+
+	closed, wins, err = database.SetGameConsensus(ctx, game.ID, "fixed:2")
+	if err != nil {
+		t.Fatalf("SetGameConsensus: %v", err)
+	}
+	if len(closed) != 1 || closed[0].DisplayID != 1 {
+		t.Fatalf("lowering the rule closed %v, want event #1", closed)
+	}
+	stored, err := database.GetWins(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetWins: %v", err)
+	}
+	if len(wins) == 0 || len(stored) != len(wins) {
+		t.Errorf("lowering the rule recorded %d wins and %d are stored, want the same non-zero number", len(wins), len(stored))
+	}
+	event, err := database.GetEventByDisplayID(ctx, game.ID, 1)
+	if err != nil || event.Status != string(EventStatusClosed) {
+		t.Errorf("event #1 = %+v, %v; want it closed", event, err)
+	}
+}
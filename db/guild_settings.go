@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// GuildSettings holds a guild's bot configuration
+type GuildSettings struct {
+	GuildID     int64
+	AdminRoleID int64 // role allowed to manage all games; 0 if unset
+}
+
+// GetGuildSettings retrieves a guild's settings (returns nil if none have been saved)
+func (db *DB) GetGuildSettings(ctx context.Context, guildID int64) (*GuildSettings, error) {
+	var settings GuildSettings
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT guild_id, COALESCE(admin_role_id, 0) FROM guild_settings WHERE guild_id = ?",
+		guildID,
+	).Scan(&settings.GuildID, &settings.AdminRoleID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SetAdminRole sets a guild's admin role; a roleID of 0 clears it
+func (db *DB) SetAdminRole(ctx context.Context, guildID, roleID int64) error {
+	var role any
+	if roleID != 0 {
+		role = roleID
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO guild_settings (guild_id, admin_role_id) VALUES (?, ?)
+		 ON CONFLICT (guild_id) DO UPDATE SET admin_role_id = excluded.admin_role_id`,
+		guildID, role,
+	)
+	return err
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// GameState is a game's position in its lifecycle
+type GameState string
+
+const (
+	GameStateDraft    GameState = "draft"    // being prepared; can be edited but not voted on
+	GameStateRunning  GameState = "running"  // accepting votes
+	GameStatePaused   GameState = "paused"   // votes are rejected until it resumes
+	GameStateFinished GameState = "finished" // boards and results are frozen
+	GameStateArchived GameState = "archived" // finished and hidden from listings
+)
+
+// GameStates lists every state in lifecycle order
+var GameStates = []GameState{GameStateDraft, GameStateRunning, GameStatePaused, GameStateFinished, GameStateArchived}
+
+// gameTransitions lists the states each state may move to
+var gameTransitions = map[GameState][]GameState{
+	GameStateDraft:    {GameStateRunning},
+	GameStateRunning:  {GameStatePaused, GameStateFinished},
+	GameStatePaused:   {GameStateRunning, GameStateFinished},
+	GameStateFinished: {GameStateArchived},
+	GameStateArchived: {GameStateFinished},
+}
+
+// Errors returned for actions the game's state doesn't allow
+var (
+	ErrInvalidTransition = errors.New("invalid game state transition")
+	ErrGameNotRunning    = errors.New("game is not running")
+	ErrLobbyOpen         = errors.New("lobby has not started")
+)
+
+// CanBecome reports whether a game in state s may move to state to
+func (s GameState) CanBecome(to GameState) bool {
+	for _, next := range gameTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the states a game in state s may move to
+func (s GameState) Next() []GameState {
+	return gameTransitions[s]
+}
+
+// Editable reports whether a game's rules and events may still change
+func (s GameState) Editable() bool {
+	return s == GameStateDraft || s == GameStateRunning || s == GameStatePaused
+}
+
+// standing is one entry in a finished game's recorded result
+type standing struct {
+	Stage  int   `json:"stage"`
+	Place  int   `json:"place"`
+	UserID int64 `json:"user_id"`
+}
+
+// SetGameState moves a game to a new state, returning ErrInvalidTransition if
+// its current state doesn't allow it, or ErrLobbyOpen for a lobby that hasn't
+// started. Finishing a game records its result; archiving one also makes it
+// inactive.
+func (db *DB) SetGameState(ctx context.Context, gameID int64, to GameState) error {
+	return db.WithTx(ctx, func(tx *sql.Tx) error {
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if game == nil {
+			return fmt.Errorf("game %d not found", gameID)
+		}
+		from := GameState(game.State)
+		if !from.CanBecome(to) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+		}
+		if game.Lobby {
+			// Boards are only dealt when the lobby starts
+			return ErrLobbyOpen
+		}
+
+		switch to {
+		case GameStateFinished:
+			if from == GameStateArchived {
+				// Unarchiving keeps the result recorded when the game first finished
+				break
+			}
+			wins, err := getWins(ctx, tx, gameID)
+			if err != nil {
+				return err
+			}
+			standings := make([]standing, len(wins))
+			for n, win := range wins {
+				standings[n] = standing{Stage: win.Stage, Place: win.Place, UserID: win.UserID}
+			}
+			result, err := json.Marshal(standings)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx,
+				"UPDATE games SET finished_at = CURRENT_TIMESTAMP, result = ? WHERE game_id = ?",
+				string(result), gameID,
+			); err != nil {
+				return err
+			}
+		case GameStateArchived:
+			if _, err := tx.ExecContext(ctx, "UPDATE games SET is_active = 0 WHERE game_id = ?", gameID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE games SET state = ? WHERE game_id = ? AND state = ?",
+			to, gameID, from,
+		)
+		return err
+	})
+}
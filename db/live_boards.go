@@ -0,0 +1,48 @@
+package db
+
+import "context"
+
+// LiveBoard is a posted board message that is kept up to date as events close
+type LiveBoard struct {
+	MessageID int64
+	ChannelID int64
+	GameID    int64
+	UserID    int64 // whose board the message shows
+}
+
+// TrackBoard records a board message so it is refreshed as the game changes
+func (db *DB) TrackBoard(ctx context.Context, board LiveBoard) error {
+	_, err := db.conn.ExecContext(ctx,
+		"INSERT OR REPLACE INTO live_boards (message_id, channel_id, game_id, user_id) VALUES (?, ?, ?, ?)",
+		board.MessageID, board.ChannelID, board.GameID, board.UserID,
+	)
+	return err
+}
+
+// UntrackBoard stops refreshing a board message, e.g. once it has been deleted
+func (db *DB) UntrackBoard(ctx context.Context, messageID int64) error {
+	_, err := db.conn.ExecContext(ctx, "DELETE FROM live_boards WHERE message_id = ?", messageID)
+	return err
+}
+
+// GetLiveBoards returns a game's tracked board messages, oldest first
+func (db *DB) GetLiveBoards(ctx context.Context, gameID int64) ([]LiveBoard, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT message_id, channel_id, game_id, user_id FROM live_boards WHERE game_id = ? ORDER BY created_at, message_id",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var boards []LiveBoard
+	for rows.Next() {
+		var board LiveBoard
+		if err := rows.Scan(&board.MessageID, &board.ChannelID, &board.GameID, &board.UserID); err != nil {
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+	return boards, rows.Err()
+}
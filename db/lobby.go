@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Errors returned for lobby sign-ups
+var (
+	ErrLobbyClosed   = errors.New("lobby closed")
+	ErrAlreadyJoined = errors.New("already joined")
+	ErrNotJoined     = errors.New("not joined")
+	ErrLobbyEmpty    = errors.New("lobby empty")
+)
+
+// JoinLobby signs a user up for a game whose lobby is open
+func (db *DB) JoinLobby(ctx context.Context, gameID, userID int64) error {
+	return db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := checkLobbyOpen(ctx, tx, gameID); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO lobby_players (game_id, user_id) VALUES (?, ?)",
+			gameID, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrAlreadyJoined
+		}
+		return nil
+	})
+}
+
+// LeaveLobby takes a user off an open lobby's roster
+func (db *DB) LeaveLobby(ctx context.Context, gameID, userID int64) error {
+	return db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := checkLobbyOpen(ctx, tx, gameID); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx,
+			"DELETE FROM lobby_players WHERE game_id = ? AND user_id = ?",
+			gameID, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotJoined
+		}
+		return nil
+	})
+}
+
+// GetLobbyPlayers returns a lobby's roster in the order players joined
+func (db *DB) GetLobbyPlayers(ctx context.Context, gameID int64) ([]int64, error) {
+	return lobbyPlayers(ctx, db.conn, gameID)
+}
+
+// StartLobby closes a game's lobby inside the caller's transaction, so the
+// caller can deal boards to the returned roster in the same transaction. The
+// game starts running, dealt from its events up to dealtEvents. It returns
+// ErrLobbyClosed if the lobby already started and ErrLobbyEmpty if nobody joined.
+func (db *DB) StartLobby(ctx context.Context, tx *sql.Tx, gameID int64, dealtEvents int) ([]int64, error) {
+	if err := checkLobbyOpen(ctx, tx, gameID); err != nil {
+		return nil, err
+	}
+	players, err := lobbyPlayers(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if len(players) == 0 {
+		return nil, ErrLobbyEmpty
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE games SET lobby = 0, state = ?, dealt_events = ? WHERE game_id = ?",
+		GameStateRunning, dealtEvents, gameID,
+	); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM lobby_players WHERE game_id = ?", gameID); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// checkLobbyOpen returns ErrLobbyClosed unless the game's lobby is open
+func checkLobbyOpen(ctx context.Context, q querier, gameID int64) error {
+	var open bool
+	err := q.QueryRowContext(ctx,
+		"SELECT lobby FROM games WHERE game_id = ? AND deleted_at IS NULL",
+		gameID,
+	).Scan(&open)
+	if err == sql.ErrNoRows || (err == nil && !open) {
+		return ErrLobbyClosed
+	}
+	return err
+}
+
+// lobbyPlayers implements GetLobbyPlayers using q, which may be a transaction
+func lobbyPlayers(ctx context.Context, q querier, gameID int64) ([]int64, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT user_id FROM lobby_players WHERE game_id = ? ORDER BY joined_at, rowid",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		players = append(players, userID)
+	}
+	return players, rows.Err()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLobby(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+	game := createTestGame(t, database, Game{Lobby: true, State: string(GameStateDraft)}, 1)
+
+	start := func() ([]int64, error) {
+		var players []int64
+		err := database.WithTx(ctx, func(tx *sql.Tx) error {
+			var err error
+			players, err = database.StartLobby(ctx, tx, game.ID, 1)
+			return err
+		})
+		return players, err
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+		want error
+	}{
+		{"start empty lobby", func() error { _, err := start(); return err }, ErrLobbyEmpty},
+		{"leave without joining", func() error { return database.LeaveLobby(ctx, game.ID, 10) }, ErrNotJoined},
+		{"join", func() error { return database.JoinLobby(ctx, game.ID, 10) }, nil},
+		{"join twice", func() error { return database.JoinLobby(ctx, game.ID, 10) }, ErrAlreadyJoined},
+		{"second player joins", func() error { return database.JoinLobby(ctx, game.ID, 11) }, nil},
+		{"third player joins", func() error { return database.JoinLobby(ctx, game.ID, 12) }, nil},
+		{"leave", func() error { return database.LeaveLobby(ctx, game.ID, 11) }, nil},
+		{"leave twice", func() error { return database.LeaveLobby(ctx, game.ID, 11) }, ErrNotJoined},
+		{"start", func() error {
+			players, err := start()
+			if err == nil && !slices.Equal(players, []int64{10, 12}) {
+				t.Errorf("StartLobby roster = %v, want [10 12]", players)
+			}
+			return err
+		}, nil},
+		{"join after start", func() error { return database.JoinLobby(ctx, game.ID, 13) }, ErrLobbyClosed},
+		{"leave after start", func() error { return database.LeaveLobby(ctx, game.ID, 10) }, ErrLobbyClosed},
+		{"start twice", func() error { _, err := start(); return err }, ErrLobbyClosed},
+	}
+	for _, step := range steps {
+		if err := step.do(); !errors.Is(err, step.want) {
+			t.Fatalf("%s = %v, want %v", step.name, err, step.want)
+		}
+	}
+
+	started, err := database.GetGame(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetGame: %v", err)
+	}
+	if started.Lobby || GameState(started.State) != GameStateRunning {
+		t.Errorf("started game lobby = %t, state = %s; want a running game with its lobby closed", started.Lobby, started.State)
+	}
+	if roster, err := database.GetLobbyPlayers(ctx, game.ID); err != nil || len(roster) != 0 {
+		t.Errorf("GetLobbyPlayers after start = %v, %v; want an empty roster", roster, err)
+	}
+}
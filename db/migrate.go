@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// baselineVersion is the schema version produced by init_schema.sql
+const baselineVersion = 2
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations reads the embedded NNN_name.sql files ordered by version
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		body, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: match[2], sql: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// migrate brings the schema up to the latest embedded migration.
+// It refuses to run against a database written by a newer binary.
+func migrate(ctx context.Context, pool *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	latest := baselineVersion
+	if len(migrations) > 0 && migrations[len(migrations)-1].version > latest {
+		latest = migrations[len(migrations)-1].version
+	}
+
+	// Pin a single connection so the foreign_keys pragma applies to the migration transactions
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	); err != nil {
+		return err
+	}
+
+	current, err := currentVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d); refusing to start", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	// Table rebuilds require foreign keys off; they are re-checked before each commit
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	if current < 0 {
+		if err := applyMigration(ctx, conn, migration{version: baselineVersion, name: "init_schema", sql: initSchemaSQL}); err != nil {
+			return err
+		}
+		current = baselineVersion
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currentVersion returns the applied schema version, or -1 for an empty database.
+// Databases created before schema_migrations existed are stamped with a detected version.
+func currentVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var version sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, err
+	}
+	if version.Valid {
+		return int(version.Int64), nil
+	}
+
+	var tables int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'games'",
+	).Scan(&tables); err != nil {
+		return 0, err
+	}
+	if tables == 0 {
+		return -1, nil
+	}
+
+	detected, err := detectLegacyVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if detected > 0 {
+		if _, err := conn.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+			detected, "detected",
+		); err != nil {
+			return 0, err
+		}
+	}
+	log.Printf("db: existing unversioned schema detected at version %d", detected)
+	return detected, nil
+}
+
+// detectLegacyVersion inspects a pre-versioning database to work out which migrations it already has
+func detectLegacyVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var hasDisplayID int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM pragma_table_info('events') WHERE name = 'display_id'",
+	).Scan(&hasDisplayID); err != nil {
+		return 0, err
+	}
+	if hasDisplayID == 0 {
+		return 0, nil
+	}
+
+	// 001 leaves foreign keys pointing at the *_new tables until 002 rebuilds them
+	var staleRefs int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND sql LIKE '%REFERENCES %\\_new(%' ESCAPE '\\'",
+	).Scan(&staleRefs); err != nil {
+		return 0, err
+	}
+	if staleRefs > 0 {
+		return 1, nil
+	}
+	return 2, nil
+}
+
+// applyMigration runs a single migration and records it, all in one transaction
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", m.version, m.name, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	violations := 0
+	for rows.Next() {
+		violations++
+	}
+	rows.Close()
+	if violations > 0 {
+		return fmt.Errorf("migration %03d_%s: %d foreign key violations", m.version, m.name, violations)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+		m.version, m.name,
+	); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("db: applied migration %03d_%s", m.version, m.name)
+	return nil
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// legacySchemaV0 is the schema from before migration 001, when events were
+// keyed by (event_id, game_id) and numbered by event_id
+const legacySchemaV0 = `
+CREATE TABLE games (
+    game_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT,
+    is_active BOOLEAN DEFAULT 0,
+    grid_size INTEGER DEFAULT 4
+);
+CREATE TABLE events (
+    event_id INTEGER,
+    game_id INTEGER,
+    description TEXT NOT NULL,
+    status TEXT DEFAULT 'OPEN',
+    PRIMARY KEY (event_id, game_id)
+);
+CREATE TABLE boards (
+    board_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    grid_size INTEGER DEFAULT 4
+);
+CREATE TABLE board_squares (
+    board_id INTEGER,
+    row INTEGER,
+    column INTEGER,
+    event_id INTEGER NOT NULL,
+    PRIMARY KEY (board_id, row, column)
+);
+CREATE TABLE votes (
+    event_id INTEGER,
+    game_id INTEGER,
+    user_id INTEGER,
+    voted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, game_id, user_id)
+);
+`
+
+// legacyGame is one game as the bot stored it before versioning; events only
+// gained display IDs in migration 001, so they are filled in for later schemas
+const legacyGame = `
+INSERT INTO games (game_id, title, is_active, grid_size) VALUES (1, 'Legacy', 1, 2);
+INSERT INTO boards (board_id, game_id, user_id, grid_size) VALUES (1, 1, 100, 2);
+INSERT INTO board_squares (board_id, row, column, event_id) VALUES
+    (1, 0, 0, 1), (1, 0, 1, 2), (1, 1, 0, 3), (1, 1, 1, 4);
+`
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	latest := migrations[len(migrations)-1].version
+
+	tests := []struct {
+		name     string
+		schema   string
+		detected int // version stamped as "detected", or 0 if nothing is
+	}{
+		{
+			name: "before 001",
+			schema: legacySchemaV0 + legacyGame + `
+INSERT INTO events (event_id, game_id, description, status) VALUES
+    (1, 1, 'first', 'CLOSED'), (2, 1, 'second', 'OPEN'), (3, 1, 'third', 'OPEN'), (4, 1, 'fourth', 'OPEN');
+INSERT INTO votes (event_id, game_id, user_id) VALUES (1, 1, 100);
+`,
+		},
+		{
+			name: "baseline",
+			schema: initSchemaSQL + legacyGame + `
+INSERT INTO events (event_id, game_id, display_id, description, status) VALUES
+    (1, 1, 1, 'first', 'CLOSED'), (2, 1, 2, 'second', 'OPEN'), (3, 1, 3, 'third', 'OPEN'), (4, 1, 4, 'fourth', 'OPEN');
+INSERT INTO votes (event_id, user_id) VALUES (1, 100);
+`,
+			detected: baselineVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "bingo.db")
+
+			// Build a database with the legacy schema but no version table
+			legacy, err := sql.Open("sqlite3", path)
+			if err != nil {
+				t.Fatalf("opening legacy database: %v", err)
+			}
+			if _, err := legacy.Exec(tt.schema); err != nil {
+				t.Fatalf("building legacy database: %v", err)
+			}
+			legacy.Close()
+
+			t.Setenv("DB_PATH", path)
+			database, err := InitDB()
+			if err != nil {
+				t.Fatalf("InitDB on legacy database: %v", err)
+			}
+			defer func() { database.Close() }()
+
+			var detected int
+			err = database.conn.QueryRow("SELECT version FROM schema_migrations WHERE name = 'detected'").Scan(&detected)
+			if err != nil && err != sql.ErrNoRows {
+				t.Fatalf("reading detected version: %v", err)
+			}
+			if detected != tt.detected {
+				t.Errorf("detected version = %d, want %d", detected, tt.detected)
+			}
+			wantApplied := latest
+			if tt.detected > 0 {
+				wantApplied = latest - tt.detected + 1
+			}
+			var current, applied int
+			if err := database.conn.QueryRow("SELECT MAX(version), COUNT(*) FROM schema_migrations").Scan(&current, &applied); err != nil {
+				t.Fatalf("reading schema version: %v", err)
+			}
+			if current != latest || applied != wantApplied {
+				t.Errorf("schema_migrations has %d rows up to version %d, want %d rows up to %d", applied, current, wantApplied, latest)
+			}
+
+			// Columns and tables added by later migrations
+			columns := []struct{ table, column string }{
+				{"games", "channel_id"},
+				{"games", "win_pattern"},
+				{"games", "consensus"},
+				{"games", "seed"},
+				{"games", "dealing"},
+				{"events", "display_id"},
+				{"events", "category"},
+				{"events", "closed_at"},
+				{"events", "retire_mode"},
+				{"board_squares", "kind"},
+				{"event_panels", "page"},
+			}
+			for _, c := range columns {
+				var n int
+				if err := database.conn.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", c.table, c.column).Scan(&n); err != nil {
+					t.Fatalf("inspecting %s: %v", c.table, err)
+				}
+				if n != 1 {
+					t.Errorf("%s.%s missing after migration", c.table, c.column)
+				}
+			}
+
+			// No foreign key may still point at a table renamed away by 001
+			var stale int
+			if err := database.conn.QueryRow(
+				"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND sql LIKE '%\\_new(%' ESCAPE '\\'",
+			).Scan(&stale); err != nil {
+				t.Fatalf("inspecting foreign keys: %v", err)
+			}
+			if stale > 0 {
+				t.Errorf("%d tables still reference *_new tables", stale)
+			}
+
+			// Legacy rows survive the table rebuilds
+			game, err := database.GetGame(ctx, 1)
+			if err != nil || game == nil {
+				t.Fatalf("GetGame(1) = %v, %v", game, err)
+			}
+			if game.Title != "Legacy" || game.GridSize != 2 {
+				t.Errorf("migrated game = %q size %d, want %q size 2", game.Title, game.GridSize, "Legacy")
+			}
+			events, err := database.GetGameEvents(ctx, 1)
+			if err != nil {
+				t.Fatalf("GetGameEvents: %v", err)
+			}
+			if len(events) != 4 || events[0].DisplayID != 1 || events[0].Status != string(EventStatusClosed) {
+				t.Errorf("migrated events = %+v, want 4 with #1 closed", events)
+			}
+			squares, err := database.GetGameSquares(ctx, 1)
+			if err != nil {
+				t.Fatalf("GetGameSquares: %v", err)
+			}
+			if len(squares[100]) != 4 {
+				t.Errorf("migrated board has %d squares, want 4", len(squares[100]))
+			}
+			if voted, err := database.HasUserVoted(ctx, events[0].ID, 100); err != nil || !voted {
+				t.Errorf("HasUserVoted after migration = %t, %v; want the legacy vote kept", voted, err)
+			}
+
+			// A second start finds nothing to do
+			database.Close()
+			database, err = InitDB()
+			if err != nil {
+				t.Fatalf("InitDB on migrated database: %v", err)
+			}
+			if err := database.conn.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+				t.Fatalf("reading schema version: %v", err)
+			}
+			if applied != wantApplied {
+				t.Errorf("restart applied migrations again: %d rows in schema_migrations", applied)
+			}
+		})
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	database := openTestDB(t)
+	if _, err := database.conn.Exec(
+		"INSERT INTO schema_migrations (version, name) SELECT MAX(version) + 1, 'from_the_future' FROM schema_migrations",
+	); err != nil {
+		t.Fatalf("stamping a newer version: %v", err)
+	}
+	database.Close()
+
+	newer, err := InitDB()
+	if err == nil {
+		newer.Close()
+		t.Fatal("InitDB on a newer schema succeeded, want it refused")
+	}
+	if !strings.Contains(err.Error(), "newer than this binary supports") {
+		t.Errorf("InitDB error = %v, want it to say the schema is newer", err)
+	}
+}
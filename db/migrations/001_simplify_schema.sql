@@ -6,8 +6,6 @@
 -- 4. Enforces single active game via unique index
 -- 5. Adds CHECK constraint for event status
 
-BEGIN TRANSACTION;
-
 -- Create new tables with improved schema
 CREATE TABLE games_new (
     game_id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -94,11 +92,6 @@ ALTER TABLE boards_new RENAME TO boards;
 ALTER TABLE board_squares_new RENAME TO board_squares;
 ALTER TABLE votes_new RENAME TO votes;
 
--- Enable foreign keys (must be set per connection)
-PRAGMA foreign_keys = ON;
-
-COMMIT;
-
 -- Verification queries (run these after migration to check integrity)
 -- Check all board_squares reference valid events:
 --   SELECT COUNT(*) FROM board_squares bs LEFT JOIN events e ON bs.event_id = e.event_id WHERE e.event_id IS NULL;
@@ -3,8 +3,6 @@
 -- so tables still reference old table names (events_new, games_new, boards_new).
 -- This migration recreates affected tables with correct foreign key references.
 
-BEGIN TRANSACTION;
-
 -- Recreate events with correct FK reference to games
 CREATE TABLE events_fixed (
     event_id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -60,6 +58,3 @@ INSERT INTO votes_fixed SELECT * FROM votes;
 DROP TABLE votes;
 ALTER TABLE votes_fixed RENAME TO votes;
 CREATE INDEX idx_votes_event ON votes(event_id);
-
-COMMIT;
-
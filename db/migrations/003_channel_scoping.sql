@@ -0,0 +1,10 @@
+-- Scope games to the guild and channel they were created in.
+-- Each channel can have its own active game, replacing the single global one.
+-- Games created before this migration have no owner until claimed via CHANNEL_ID.
+
+ALTER TABLE games ADD COLUMN guild_id INTEGER;
+ALTER TABLE games ADD COLUMN channel_id INTEGER;
+
+DROP INDEX idx_active_game;
+CREATE UNIQUE INDEX idx_active_game ON games(channel_id) WHERE is_active = 1;
+CREATE INDEX idx_games_channel ON games(channel_id);
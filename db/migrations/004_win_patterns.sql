@@ -0,0 +1,4 @@
+-- Per-game win condition, stored as a pattern spec (see rules.ParsePattern).
+-- Existing games keep the original any-line rule.
+
+ALTER TABLE games ADD COLUMN win_pattern TEXT NOT NULL DEFAULT 'line';
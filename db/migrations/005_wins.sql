@@ -0,0 +1,20 @@
+-- Persist winners so each is announced once, in finishing order.
+-- games.win_pattern may now hold several stages separated by ">" (see rules.ParseStages);
+-- each stage awards prize_places places before play moves on to the next.
+
+ALTER TABLE games ADD COLUMN prize_places INTEGER NOT NULL DEFAULT 3;
+
+CREATE TABLE wins (
+    win_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    stage INTEGER NOT NULL,
+    place INTEGER NOT NULL,
+    event_id INTEGER NOT NULL,
+    won_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(game_id, user_id, stage),
+    FOREIGN KEY (game_id) REFERENCES games(game_id),
+    FOREIGN KEY (event_id) REFERENCES events(event_id)
+);
+
+CREATE INDEX idx_wins_game ON wins(game_id);
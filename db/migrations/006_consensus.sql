@@ -0,0 +1,6 @@
+-- Per-game vote consensus rule (see rules.ParseConsensus) and the game's host,
+-- who may confirm events under the host rule and change the rule later.
+-- Games created before this migration have no recorded host.
+
+ALTER TABLE games ADD COLUMN consensus TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE games ADD COLUMN host_id INTEGER;
@@ -0,0 +1,11 @@
+-- Per-game voting eligibility (see rules.ParseEligibility) and the referees
+-- whose votes count alongside the players' under the "referees" rule.
+
+ALTER TABLE games ADD COLUMN eligibility TEXT NOT NULL DEFAULT 'players';
+
+CREATE TABLE game_referees (
+    game_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    PRIMARY KEY (game_id, user_id),
+    FOREIGN KEY (game_id) REFERENCES games(game_id)
+);
@@ -0,0 +1,7 @@
+-- Per-guild settings. admin_role_id names a role whose members may manage
+-- every game in the guild, alongside members with Manage Server.
+
+CREATE TABLE guild_settings (
+    guild_id INTEGER PRIMARY KEY,
+    admin_role_id INTEGER
+);
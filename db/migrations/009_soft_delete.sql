@@ -0,0 +1,4 @@
+-- Soft deletion: deleted games keep their data, hidden from listings, until
+-- restored or purged once the retention period has passed.
+
+ALTER TABLE games ADD COLUMN deleted_at TIMESTAMP;
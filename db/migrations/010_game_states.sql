@@ -0,0 +1,8 @@
+-- Explicit game lifecycle (see db.GameState). Existing games are already being
+-- played, so they start out running. finished_at and result are set when a game
+-- finishes; result is a JSON snapshot of its standings at that moment.
+
+ALTER TABLE games ADD COLUMN state TEXT NOT NULL DEFAULT 'running'
+    CHECK (state IN ('draft', 'running', 'paused', 'finished', 'archived'));
+ALTER TABLE games ADD COLUMN finished_at TIMESTAMP;
+ALTER TABLE games ADD COLUMN result TEXT;
@@ -0,0 +1,42 @@
+-- Event editing: events can be RETIRED, and a board square either shows an
+-- event or is FREE (always marked, with no event). SQLite can't alter CHECK
+-- constraints or NOT NULL in place, so both tables are rebuilt.
+
+CREATE TABLE events_new (
+    event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_id INTEGER NOT NULL,
+    display_id INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('OPEN', 'CLOSED', 'RETIRED')) DEFAULT 'OPEN',
+    UNIQUE(game_id, display_id),
+    FOREIGN KEY (game_id) REFERENCES games(game_id)
+);
+
+INSERT INTO events_new (event_id, game_id, display_id, description, status)
+SELECT event_id, game_id, display_id, description, status FROM events;
+
+DROP TABLE events;
+ALTER TABLE events_new RENAME TO events;
+
+CREATE INDEX idx_events_game ON events(game_id);
+CREATE INDEX idx_events_display ON events(game_id, display_id);
+
+CREATE TABLE board_squares_new (
+    board_id INTEGER,
+    row INTEGER,
+    column INTEGER,
+    kind TEXT NOT NULL CHECK (kind IN ('EVENT', 'FREE')) DEFAULT 'EVENT',
+    event_id INTEGER,
+    PRIMARY KEY (board_id, row, column),
+    CHECK ((kind = 'EVENT') = (event_id IS NOT NULL)),
+    FOREIGN KEY (board_id) REFERENCES boards(board_id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES events(event_id)
+);
+
+INSERT INTO board_squares_new (board_id, row, column, kind, event_id)
+SELECT board_id, row, column, 'EVENT', event_id FROM board_squares;
+
+DROP TABLE board_squares;
+ALTER TABLE board_squares_new RENAME TO board_squares;
+
+CREATE INDEX idx_board_squares_event ON board_squares(event_id);
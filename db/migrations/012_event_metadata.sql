@@ -0,0 +1,9 @@
+-- Event metadata from rich CSV imports. weight biases how many boards an event
+-- lands on, category spreads events across each board, tags are free-form
+-- comma-separated labels, and free events start CLOSED so their squares are
+-- pre-marked.
+
+ALTER TABLE events ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN weight REAL NOT NULL DEFAULT 1;
+ALTER TABLE events ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN is_free BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,23 @@
+-- Saved event packs: named, per-guild event lists that new_game can use instead
+-- of an uploaded CSV. Pack events keep the metadata added in 012.
+
+CREATE TABLE event_packs (
+    pack_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id INTEGER NOT NULL,
+    name TEXT NOT NULL COLLATE NOCASE,
+    created_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(guild_id, name)
+);
+
+CREATE TABLE event_pack_events (
+    pack_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    weight REAL NOT NULL DEFAULT 1,
+    tags TEXT NOT NULL DEFAULT '',
+    is_free BOOLEAN NOT NULL DEFAULT 0,
+    PRIMARY KEY (pack_id, position),
+    FOREIGN KEY (pack_id) REFERENCES event_packs(pack_id)
+);
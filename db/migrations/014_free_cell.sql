@@ -0,0 +1,6 @@
+-- Optional free square at the same position on every board of a game
+-- (0-based row and column; both NULL when the game has none). The square
+-- itself is stored in board_squares with kind FREE.
+
+ALTER TABLE games ADD COLUMN free_row INTEGER;
+ALTER TABLE games ADD COLUMN free_col INTEGER;
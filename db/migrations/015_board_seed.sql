@@ -0,0 +1,6 @@
+-- Reproducible boards. Boards are dealt from a local RNG seeded with seed,
+-- using the game's first dealt_events events, so they can be regenerated and
+-- checked later. Both are NULL for games dealt before seeding.
+
+ALTER TABLE games ADD COLUMN seed INTEGER;
+ALTER TABLE games ADD COLUMN dealt_events INTEGER;
@@ -0,0 +1,4 @@
+-- How a game's boards were dealt. Fair dealing reshuffles the standard deal,
+-- so verify_boards needs to know which one to replay.
+
+ALTER TABLE games ADD COLUMN dealing TEXT NOT NULL DEFAULT 'standard' CHECK (dealing IN ('standard', 'fair'));
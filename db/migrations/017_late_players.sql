@@ -0,0 +1,16 @@
+-- Players can join or leave a game after it is dealt. A late joiner's board is
+-- dealt on its own from seed using the game's first dealt_events events; both
+-- are NULL for boards dealt with the game. removed_players remembers who left,
+-- so verify_boards can still replay the original deal.
+
+ALTER TABLE boards ADD COLUMN seed INTEGER;
+ALTER TABLE boards ADD COLUMN dealt_events INTEGER;
+
+CREATE TABLE removed_players (
+    game_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    dealt_with_game BOOLEAN NOT NULL,
+    removed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (game_id, user_id),
+    FOREIGN KEY (game_id) REFERENCES games(game_id)
+);
@@ -0,0 +1,13 @@
+-- Lobby games: players sign themselves up before boards are dealt. While lobby
+-- is set the game is a draft with no boards, and lobby_players holds the roster;
+-- starting the lobby deals a board to everyone on it and clears the roster.
+
+ALTER TABLE games ADD COLUMN lobby BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE TABLE lobby_players (
+    game_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (game_id, user_id),
+    FOREIGN KEY (game_id) REFERENCES games(game_id)
+);
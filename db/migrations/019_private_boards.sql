@@ -0,0 +1,6 @@
+-- Secret boards. While private_boards is set, players can only see their own
+-- board until the game finishes. dm_boards sends every player their board by
+-- direct message when voting opens.
+
+ALTER TABLE games ADD COLUMN private_boards BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN dm_boards BOOLEAN NOT NULL DEFAULT 0;
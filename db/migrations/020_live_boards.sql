@@ -0,0 +1,14 @@
+-- Live boards: board messages the bot keeps up to date. Each row is a posted
+-- board image that is re-rendered and edited in place whenever the game's
+-- events close or change.
+
+CREATE TABLE live_boards (
+    message_id INTEGER PRIMARY KEY,
+    channel_id INTEGER NOT NULL,
+    game_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (game_id) REFERENCES games(game_id)
+);
+
+CREATE INDEX idx_live_boards_game ON live_boards(game_id);
@@ -0,0 +1,5 @@
+-- When each event closed, so board images can highlight the latest square to
+-- be marked. NULL for open events, free squares and events closed before this
+-- migration.
+
+ALTER TABLE events ADD COLUMN closed_at TIMESTAMP;
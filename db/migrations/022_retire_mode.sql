@@ -0,0 +1,5 @@
+-- How each retired event's squares were handled, so boards dealt later treat
+-- them the same way. NULL for events that aren't retired; events retired
+-- before this migration are treated as replaced.
+
+ALTER TABLE events ADD COLUMN retire_mode TEXT;
@@ -0,0 +1,14 @@
+-- Event panels: posted voting panels the bot keeps up to date. Each row is a
+-- panel message, with the page it shows, that is re-rendered and edited in
+-- place whenever votes are cast or events change.
+
+CREATE TABLE event_panels (
+    message_id INTEGER PRIMARY KEY,
+    channel_id INTEGER NOT NULL,
+    game_id INTEGER NOT NULL,
+    page INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (game_id) REFERENCES games(game_id)
+);
+
+CREATE INDEX idx_event_panels_game ON event_panels(game_id);
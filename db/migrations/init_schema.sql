@@ -1,6 +1,4 @@
--- Fresh database schema
-BEGIN TRANSACTION;
-
+-- Fresh database schema (equivalent to migration 002)
 CREATE TABLE games (
     game_id INTEGER PRIMARY KEY AUTOINCREMENT,
     title TEXT,
@@ -51,7 +49,3 @@ CREATE TABLE votes (
 );
 
 CREATE INDEX idx_votes_event ON votes(event_id);
-
-PRAGMA foreign_keys = ON;
-
-COMMIT;
\ No newline at end of file
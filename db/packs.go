@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrPackExists is returned when saving a pack under a name the guild already uses
+var ErrPackExists = errors.New("pack already exists")
+
+// EventPack is a named, reusable list of events saved in a guild
+type EventPack struct {
+	ID         int64
+	GuildID    int64
+	Name       string
+	CreatedBy  int64
+	CreatedAt  time.Time
+	EventCount int
+}
+
+// SavePack stores a pack's events in order. If the guild already has a pack with
+// the same name it is overwritten when replace is set, and ErrPackExists is
+// returned otherwise. Only the metadata fields of events are kept.
+func (db *DB) SavePack(ctx context.Context, pack EventPack, events []Event, replace bool) (int64, error) {
+	var packID int64
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			"SELECT pack_id FROM event_packs WHERE guild_id = ? AND name = ?",
+			pack.GuildID, pack.Name,
+		).Scan(&packID)
+		switch {
+		case err == sql.ErrNoRows:
+			result, err := tx.ExecContext(ctx,
+				"INSERT INTO event_packs (guild_id, name, created_by) VALUES (?, ?, ?)",
+				pack.GuildID, pack.Name, pack.CreatedBy,
+			)
+			if err != nil {
+				return err
+			}
+			if packID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !replace:
+			return ErrPackExists
+		default:
+			if _, err := tx.ExecContext(ctx,
+				"UPDATE event_packs SET name = ?, created_by = ?, created_at = CURRENT_TIMESTAMP WHERE pack_id = ?",
+				pack.Name, pack.CreatedBy, packID,
+			); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "DELETE FROM event_pack_events WHERE pack_id = ?", packID); err != nil {
+				return err
+			}
+		}
+
+		stmt, err := tx.PrepareContext(ctx,
+			"INSERT INTO event_pack_events (pack_id, position, description, category, weight, tags, is_free) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i, event := range events {
+			weight := event.Weight
+			if weight <= 0 {
+				weight = 1
+			}
+			if _, err := stmt.ExecContext(ctx, packID, i+1, event.Description, event.Category, weight, strings.Join(event.Tags, ","), event.Free); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return packID, err
+}
+
+// GetPack retrieves a guild's pack by name, ignoring case (returns nil if not found)
+func (db *DB) GetPack(ctx context.Context, guildID int64, name string) (*EventPack, error) {
+	var pack EventPack
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT p.pack_id, p.guild_id, p.name, p.created_by, p.created_at, COUNT(e.position)
+		 FROM event_packs p LEFT JOIN event_pack_events e ON e.pack_id = p.pack_id
+		 WHERE p.guild_id = ? AND p.name = ?
+		 GROUP BY p.pack_id`,
+		guildID, name,
+	).Scan(&pack.ID, &pack.GuildID, &pack.Name, &pack.CreatedBy, &pack.CreatedAt, &pack.EventCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// ListPacks retrieves a guild's packs by name
+func (db *DB) ListPacks(ctx context.Context, guildID int64) ([]EventPack, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT p.pack_id, p.guild_id, p.name, p.created_by, p.created_at, COUNT(e.position)
+		 FROM event_packs p LEFT JOIN event_pack_events e ON e.pack_id = p.pack_id
+		 WHERE p.guild_id = ?
+		 GROUP BY p.pack_id
+		 ORDER BY p.name`,
+		guildID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var packs []EventPack
+	for rows.Next() {
+		var pack EventPack
+		if err := rows.Scan(&pack.ID, &pack.GuildID, &pack.Name, &pack.CreatedBy, &pack.CreatedAt, &pack.EventCount); err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, rows.Err()
+}
+
+// GetPackEvents retrieves a pack's events in order. Only the description and
+// metadata fields of the returned events are set.
+func (db *DB) GetPackEvents(ctx context.Context, packID int64) ([]Event, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT description, category, weight, tags, is_free FROM event_pack_events WHERE pack_id = ? ORDER BY position",
+		packID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var tags string
+		if err := rows.Scan(&event.Description, &event.Category, &event.Weight, &tags, &event.Free); err != nil {
+			return nil, err
+		}
+		if tags != "" {
+			event.Tags = strings.Split(tags, ",")
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// DeletePack deletes a pack and its events
+func (db *DB) DeletePack(ctx context.Context, packID int64) error {
+	return db.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM event_pack_events WHERE pack_id = ?", packID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM event_packs WHERE pack_id = ?", packID)
+		return err
+	})
+}
@@ -0,0 +1,281 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fordtom/bingo/rules"
+)
+
+// Errors returned when changing a game's players
+var (
+	ErrAlreadyPlaying = errors.New("already a player")
+	ErrNotInGame      = errors.New("not a player")
+	ErrLastPlayer     = errors.New("last player")
+)
+
+// RemovedPlayer records a player taken out of a game
+type RemovedPlayer struct {
+	GameID        int64
+	UserID        int64
+	DealtWithGame bool // whether their board came from the game's original deal
+	RemovedAt     time.Time
+}
+
+// RemoveResult describes the changes made by RemovePlayer
+type RemoveResult struct {
+	WithdrawnVotes int     // the player's votes on events that were still open
+	Revoked        []Win   // the player's wins, with later places moved up
+	Closed         []Event // open events that the lower player count lets close
+	Wins           []Win   // wins newly recorded because events closed
+}
+
+// AddPlayer stores a late joiner's board, dealt after the game was created.
+// board.Seed and board.DealtEvents say how the squares were dealt, so they can
+// be replayed. Squares showing an event retired before the player joined are
+// replaced or freed as RetireEvent would have done. Closed events show as
+// marked straight away; any win they complete is awarded when the next event
+// closes. The new board's ID is returned.
+func (db *DB) AddPlayer(ctx context.Context, board Board, squares []BoardSquare) (int64, error) {
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		var playing bool
+		if err := tx.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM boards WHERE game_id = ? AND user_id = ?)",
+			board.GameID, board.UserID,
+		).Scan(&playing); err != nil {
+			return err
+		}
+		if playing {
+			return ErrAlreadyPlaying
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO boards (game_id, user_id, grid_size, seed, dealt_events) VALUES (?, ?, ?, ?, ?)",
+			board.GameID, board.UserID, board.GridSize, board.Seed, board.DealtEvents,
+		)
+		if err != nil {
+			return err
+		}
+		if board.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		if err := db.CreateBoardSquares(ctx, tx, board.ID, squares); err != nil {
+			return err
+		}
+
+		return db.ReplaceRetiredSquares(ctx, tx, board.GameID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return board.ID, nil
+}
+
+// ReplaceRetiredSquares replaces or frees newly dealt squares that show a
+// retired event, using the mode the event was retired with, as RetireEvent
+// would have done. Retiring an event already cleared it from the boards of the
+// time, so only squares dealt since are touched.
+func (db *DB) ReplaceRetiredSquares(ctx context.Context, tx *sql.Tx, gameID int64) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT DISTINCT e.event_id, COALESCE(e.retire_mode, ?) FROM board_squares bs
+		 JOIN events e ON e.event_id = bs.event_id
+		 WHERE e.game_id = ? AND e.status = ?`,
+		RetireReplace, gameID, EventStatusRetired,
+	)
+	if err != nil {
+		return err
+	}
+	type retiredEvent struct {
+		id   int64
+		mode RetireMode
+	}
+	var retired []retiredEvent
+	for rows.Next() {
+		var event retiredEvent
+		if err := rows.Scan(&event.id, &event.mode); err != nil {
+			rows.Close()
+			return err
+		}
+		retired = append(retired, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, event := range retired {
+		if event.mode == RetireReplace {
+			if _, err := replaceSquares(ctx, tx, gameID, event.id, 0); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE board_squares SET kind = ?, event_id = NULL WHERE event_id = ?",
+			SquareKindFree, event.id,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemovePlayer takes a player out of a game. Their board is deleted and no longer
+// kept live, their votes on open events are withdrawn (votes on closed events
+// stand), and their wins are revoked so later finishers move up. With one player
+// fewer, open events that already have enough votes are closed and their wins
+// recorded, while the game is running. The last player can't be removed.
+func (db *DB) RemovePlayer(ctx context.Context, gameID, userID int64) (*RemoveResult, error) {
+	result := &RemoveResult{}
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		var boardID int64
+		var dealtWithGame bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT board_id, seed IS NULL FROM boards WHERE game_id = ? AND user_id = ?",
+			gameID, userID,
+		).Scan(&boardID, &dealtWithGame)
+		if err == sql.ErrNoRows {
+			return ErrNotInGame
+		}
+		if err != nil {
+			return err
+		}
+		var players int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM boards WHERE game_id = ?", gameID).Scan(&players); err != nil {
+			return err
+		}
+		if players <= 1 {
+			return ErrLastPlayer
+		}
+
+		// Deleting the board cascades to its squares; its live messages go with it
+		if _, err := tx.ExecContext(ctx, "DELETE FROM boards WHERE board_id = ?", boardID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM live_boards WHERE game_id = ? AND user_id = ?", gameID, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO removed_players (game_id, user_id, dealt_with_game) VALUES (?, ?, ?)
+			 ON CONFLICT (game_id, user_id) DO UPDATE SET
+			   dealt_with_game = dealt_with_game OR excluded.dealt_with_game,
+			   removed_at = CURRENT_TIMESTAMP`,
+			gameID, userID, dealtWithGame,
+		); err != nil {
+			return err
+		}
+
+		withdrawn, err := tx.ExecContext(ctx,
+			"DELETE FROM votes WHERE user_id = ? AND event_id IN (SELECT event_id FROM events WHERE game_id = ? AND status = ?)",
+			userID, gameID, EventStatusOpen,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := withdrawn.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result.WithdrawnVotes = int(n)
+
+		if result.Revoked, err = revokeInvalidWins(ctx, tx, gameID); err != nil {
+			return err
+		}
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if game != nil && GameState(game.State) == GameStateRunning {
+			result.Closed, result.Wins, err = closeReachedEvents(ctx, tx, game)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRemovedPlayers returns the players taken out of a game, in the order they left
+func (db *DB) GetRemovedPlayers(ctx context.Context, gameID int64) ([]RemovedPlayer, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT game_id, user_id, dealt_with_game, removed_at FROM removed_players WHERE game_id = ? ORDER BY removed_at, user_id",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var removed []RemovedPlayer
+	for rows.Next() {
+		var player RemovedPlayer
+		if err := rows.Scan(&player.GameID, &player.UserID, &player.DealtWithGame, &player.RemovedAt); err != nil {
+			return nil, err
+		}
+		removed = append(removed, player)
+	}
+	return removed, rows.Err()
+}
+
+// closeReachedEvents closes every open event whose votes now satisfy the game's
+// consensus rule or spectator quorum, recording wins after each in display order
+func closeReachedEvents(ctx context.Context, tx *sql.Tx, game *Game) ([]Event, []Win, error) {
+	consensus, err := rules.ParseConsensus(game.Consensus)
+	if err != nil {
+		return nil, nil, err
+	}
+	eligibility, err := rules.ParseEligibility(game.Eligibility)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+eventColumns+" FROM events WHERE game_id = ? AND status = ? ORDER BY display_id",
+		game.ID, EventStatusOpen,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var open []Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		open = append(open, *event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var closed []Event
+	var wins []Win
+	for _, event := range open {
+		tally, err := eventTally(ctx, tx, game, event.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !consensus.Reached(tally) && !eligibility.QuorumReached(tally) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE events SET status = ?, closed_at = CURRENT_TIMESTAMP WHERE event_id = ?",
+			EventStatusClosed, event.ID,
+		); err != nil {
+			return nil, nil, err
+		}
+		event.Status = string(EventStatusClosed)
+		closed = append(closed, event)
+
+		newWins, err := recordWins(ctx, tx, game.ID, event.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		wins = append(wins, newWins...)
+	}
+	return closed, wins, nil
+}
@@ -2,8 +2,134 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fordtom/bingo/rules"
+)
+
+// Errors returned by CastVote when a vote can't be recorded
+var (
+	ErrEventNotFound = errors.New("event not found")
+	ErrEventClosed   = errors.New("event already closed")
+	ErrEventRetired  = errors.New("event retired")
+	ErrAlreadyVoted  = errors.New("already voted")
+	ErrNotPlayer     = errors.New("only players may vote")
+	ErrNotReferee    = errors.New("only players and referees may vote")
 )
 
+// VoteResult describes a vote recorded by CastVote
+type VoteResult struct {
+	Event       Event
+	Consensus   rules.Consensus
+	Eligibility rules.Eligibility
+	Tally       rules.Tally     // counts after the vote
+	Spectator   bool            // whether the vote was cast by a spectator
+	Closed      bool            // whether this vote closed the event
+	Wins        []Win           // wins newly recorded because the event closed
+	Stages      []rules.Pattern // the game's win stages, for formatting Wins
+}
+
+// CastVote records a user's vote on an event and, if that meets the game's
+// consensus rule or spectator quorum, closes the event and records new winners. Everything happens
+// in one transaction, so only one of several simultaneous votes can close an
+// event and announce its winners.
+func (db *DB) CastVote(ctx context.Context, gameID int64, displayID int, userID int64) (*VoteResult, error) {
+	var result *VoteResult
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if game == nil || game.DeletedAt != nil {
+			return fmt.Errorf("game %d not found", gameID)
+		}
+		if GameState(game.State) != GameStateRunning {
+			return fmt.Errorf("%w: %s", ErrGameNotRunning, game.State)
+		}
+		consensus, err := rules.ParseConsensus(game.Consensus)
+		if err != nil {
+			return err
+		}
+		eligibility, err := rules.ParseEligibility(game.Eligibility)
+		if err != nil {
+			return err
+		}
+
+		event, err := scanEvent(tx.QueryRowContext(ctx,
+			"SELECT "+eventColumns+" FROM events WHERE game_id = ? AND display_id = ?",
+			gameID, displayID,
+		))
+		if err == sql.ErrNoRows {
+			return ErrEventNotFound
+		}
+		if err != nil {
+			return err
+		}
+		switch EventStatus(event.Status) {
+		case EventStatusClosed:
+			return ErrEventClosed
+		case EventStatusRetired:
+			return ErrEventRetired
+		}
+		spectator, err := checkEligible(ctx, tx, game, eligibility, userID)
+		if err != nil {
+			return err
+		}
+
+		inserted, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO votes (event_id, user_id) VALUES (?, ?)",
+			event.ID, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := inserted.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrAlreadyVoted
+		}
+
+		tally, err := eventTally(ctx, tx, game, event.ID)
+		if err != nil {
+			return err
+		}
+		vote := &VoteResult{Event: *event, Consensus: consensus, Eligibility: eligibility, Tally: tally, Spectator: spectator}
+
+		if consensus.Reached(tally) || eligibility.QuorumReached(tally) {
+			// Only the vote that flips the status gets to close the event
+			closed, err := tx.ExecContext(ctx,
+				"UPDATE events SET status = ?, closed_at = CURRENT_TIMESTAMP WHERE event_id = ? AND status = ?",
+				EventStatusClosed, event.ID, EventStatusOpen,
+			)
+			if err != nil {
+				return err
+			}
+			if n, err := closed.RowsAffected(); err != nil {
+				return err
+			} else if n == 0 {
+				return ErrEventClosed
+			}
+			vote.Closed = true
+			vote.Event.Status = string(EventStatusClosed)
+
+			if vote.Stages, err = rules.ParseStages(game.WinPattern); err != nil {
+				return err
+			}
+			if vote.Wins, err = recordWins(ctx, tx, gameID, event.ID); err != nil {
+				return err
+			}
+		}
+		result = vote
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // CreateVote records a vote for an event by a user
 func (db *DB) CreateVote(ctx context.Context, eventID, userID int64) error {
 	_, err := db.conn.ExecContext(ctx,
@@ -57,3 +183,82 @@ func (db *DB) GetEventVoters(ctx context.Context, eventID int64) ([]int64, error
 	}
 	return voters, rows.Err()
 }
+
+// GetEventTally returns the counts the game's consensus rule is evaluated against for an event
+func (db *DB) GetEventTally(ctx context.Context, game *Game, eventID int64) (rules.Tally, error) {
+	return eventTally(ctx, db.conn, game, eventID)
+}
+
+// eligibleVoter is an SQL condition on votes v that holds when the vote counts
+// toward consensus: it is a player's, the host's, or (when @referees is true) a referee's
+const eligibleVoter = `(v.user_id IN (SELECT user_id FROM boards WHERE game_id = @game)
+	OR v.user_id = @host
+	OR (@referees AND v.user_id IN (SELECT user_id FROM game_referees WHERE game_id = @game)))`
+
+// eventTally implements GetEventTally using q, which may be a transaction
+func eventTally(ctx context.Context, q querier, game *Game, eventID int64) (rules.Tally, error) {
+	eligibility, err := rules.ParseEligibility(game.Eligibility)
+	if err != nil {
+		return rules.Tally{}, err
+	}
+
+	var t rules.Tally
+	err = q.QueryRowContext(ctx,
+		`SELECT
+			(SELECT COUNT(*) FROM votes v WHERE v.event_id = @event AND `+eligibleVoter+`),
+			(SELECT COUNT(*) FROM votes v WHERE v.event_id = @event AND NOT `+eligibleVoter+`),
+			(SELECT COUNT(*) FROM boards WHERE game_id = @game),
+			(SELECT COUNT(DISTINCT v.user_id) FROM votes v JOIN events e ON e.event_id = v.event_id WHERE e.game_id = @game AND `+eligibleVoter+`),
+			EXISTS (SELECT 1 FROM votes WHERE event_id = @event AND user_id = @host)`,
+		sql.Named("event", eventID),
+		sql.Named("game", game.ID),
+		sql.Named("host", game.HostID),
+		sql.Named("referees", eligibility.Kind == rules.EligibilityReferees),
+	).Scan(&t.Votes, &t.Spectators, &t.Players, &t.ActiveVoters, &t.HostVoted)
+	return t, err
+}
+
+// checkEligible returns ErrNotPlayer or ErrNotReferee if the game's eligibility
+// rule doesn't let the user vote, and reports whether they would vote as a spectator
+func checkEligible(ctx context.Context, q querier, game *Game, eligibility rules.Eligibility, userID int64) (bool, error) {
+	if userID == game.HostID {
+		return false, nil
+	}
+	var player, referee bool
+	err := q.QueryRowContext(ctx,
+		`SELECT
+			EXISTS (SELECT 1 FROM boards WHERE game_id = ? AND user_id = ?),
+			EXISTS (SELECT 1 FROM game_referees WHERE game_id = ? AND user_id = ?)`,
+		game.ID, userID, game.ID, userID,
+	).Scan(&player, &referee)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case player:
+		return false, nil
+	case eligibility.Kind == rules.EligibilityReferees && referee:
+		return false, nil
+	case eligibility.Kind == rules.EligibilityOpen:
+		return true, nil
+	case eligibility.Kind == rules.EligibilityReferees:
+		return false, ErrNotReferee
+	}
+	return false, ErrNotPlayer
+}
+
+// DeleteVote removes a user's vote from an event that is still open.
+// It reports whether a vote was removed.
+func (db *DB) DeleteVote(ctx context.Context, eventID, userID int64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		`DELETE FROM votes WHERE event_id = ? AND user_id = ?
+		 AND EXISTS (SELECT 1 FROM events WHERE event_id = ? AND status = ?)`,
+		eventID, userID, eventID, EventStatusOpen,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestCastVoteConcurrentClose(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+	// Every board is the single square of event #1, so closing it wins for everyone
+	players := []int64{10, 11, 12, 13, 14, 15}
+	game := createTestGame(t, database, Game{Consensus: "fixed:2"}, 1, players...)
+
+	var wg sync.WaitGroup
+	results := make([]*VoteResult, len(players))
+	errs := make([]error, len(players))
+	for n, userID := range players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[n], errs[n] = database.CastVote(ctx, game.ID, 1, userID)
+		}()
+	}
+	wg.Wait()
+
+	closes, rejected := 0, 0
+	var wins []Win
+	for n := range players {
+		switch {
+		case errors.Is(errs[n], ErrEventClosed):
+			rejected++
+		case errs[n] != nil:
+			t.Fatalf("CastVote by %d: %v", players[n], errs[n])
+		case results[n].Closed:
+			closes++
+			wins = results[n].Wins
+		}
+	}
+	if closes != 1 {
+		t.Fatalf("%d votes reported closing the event, want exactly 1", closes)
+	}
+	if rejected != len(players)-2 {
+		t.Errorf("%d votes were rejected as too late, want %d", rejected, len(players)-2)
+	}
+
+	stored, err := database.GetWins(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetWins: %v", err)
+	}
+	if len(wins) == 0 || len(stored) != len(wins) {
+		t.Errorf("closing vote reported %d wins and %d are stored, want the same non-zero number", len(wins), len(stored))
+	}
+	winners := make(map[int64]bool)
+	for _, win := range stored {
+		if winners[win.UserID] {
+			t.Errorf("player %d's win was recorded twice", win.UserID)
+		}
+		winners[win.UserID] = true
+	}
+
+	// The host may vote, but not on an event that has already closed
+	if _, err := database.CastVote(ctx, game.ID, 1, game.HostID); !errors.Is(err, ErrEventClosed) {
+		t.Errorf("vote on a closed event = %v, want ErrEventClosed", err)
+	}
+}
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/fordtom/bingo/rules"
+)
+
+// GetWins returns all recorded wins for a game in the order they were awarded
+func (db *DB) GetWins(ctx context.Context, gameID int64) ([]Win, error) {
+	return getWins(ctx, db.conn, gameID)
+}
+
+// getWins returns a game's wins using q, which may be a transaction
+func getWins(ctx context.Context, q querier, gameID int64) ([]Win, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT win_id, game_id, user_id, stage, place, event_id, won_at FROM wins WHERE game_id = ? ORDER BY stage, place, win_id",
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wins []Win
+	for rows.Next() {
+		var win Win
+		if err := rows.Scan(&win.ID, &win.GameID, &win.UserID, &win.Stage, &win.Place, &win.EventID, &win.WonAt); err != nil {
+			return nil, err
+		}
+		wins = append(wins, win)
+	}
+	return wins, rows.Err()
+}
+
+// GetCurrentStage returns the game's 1-based current stage, or 0 once every stage is complete
+func (db *DB) GetCurrentStage(ctx context.Context, gameID int64) (int, error) {
+	game, err := db.GetGame(ctx, gameID)
+	if err != nil || game == nil {
+		return 0, err
+	}
+	stages, err := rules.ParseStages(game.WinPattern)
+	if err != nil {
+		return 0, err
+	}
+	wins, err := db.GetWins(ctx, gameID)
+	if err != nil {
+		return 0, err
+	}
+
+	stage := rules.CurrentStage(awardedPerStage(wins, len(stages)), game.PrizePlaces)
+	if stage == len(stages) {
+		return 0, nil
+	}
+	return stage + 1, nil
+}
+
+// RecordWins awards places to players whose boards newly complete the current
+// stage now that eventID has closed, moving on to later stages as earlier ones
+// fill up. Only the newly recorded wins are returned.
+func (db *DB) RecordWins(ctx context.Context, gameID, eventID int64) ([]Win, error) {
+	var wins []Win
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		wins, err = recordWins(ctx, tx, gameID, eventID)
+		return err
+	})
+	return wins, err
+}
+
+// recordWins implements RecordWins inside the caller's transaction
+func recordWins(ctx context.Context, tx *sql.Tx, gameID, eventID int64) ([]Win, error) {
+	game, err := getGame(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, fmt.Errorf("game %d not found", gameID)
+	}
+	stages, err := rules.ParseStages(game.WinPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	grids, err := markedGrids(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	playerIDs := make([]int64, 0, len(grids))
+	for userID := range grids {
+		playerIDs = append(playerIDs, userID)
+	}
+	sort.Slice(playerIDs, func(a, b int) bool { return playerIDs[a] < playerIDs[b] })
+
+	existing, err := getWins(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	awarded := awardedPerStage(existing, len(stages))
+	hasWon := make(map[[2]int64]bool, len(existing))
+	for _, win := range existing {
+		hasWon[[2]int64{int64(win.Stage), win.UserID}] = true
+	}
+
+	var newWins []Win
+	for stage := rules.CurrentStage(awarded, game.PrizePlaces); stage < len(stages); stage++ {
+		// Everyone completing the stage on the same event shares the place
+		place := awarded[stage] + 1
+		for _, userID := range playerIDs {
+			key := [2]int64{int64(stage + 1), userID}
+			if hasWon[key] || !stages[stage].Match(grids[userID]) {
+				continue
+			}
+			result, err := tx.ExecContext(ctx,
+				"INSERT INTO wins (game_id, user_id, stage, place, event_id) VALUES (?, ?, ?, ?, ?)",
+				gameID, userID, stage+1, place, eventID,
+			)
+			if err != nil {
+				return nil, err
+			}
+			winID, err := result.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			newWins = append(newWins, Win{ID: winID, GameID: gameID, UserID: userID, Stage: stage + 1, Place: place, EventID: eventID})
+			hasWon[key] = true
+			awarded[stage]++
+		}
+
+		// Later stages only open once this one has awarded all its places
+		if awarded[stage] < game.PrizePlaces {
+			break
+		}
+	}
+	return newWins, nil
+}
+
+// revokeInvalidWins deletes wins whose boards no longer complete their stage's
+// pattern, then renumbers the remaining places. The revoked wins are returned.
+func revokeInvalidWins(ctx context.Context, tx *sql.Tx, gameID int64) ([]Win, error) {
+	game, err := getGame(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, fmt.Errorf("game %d not found", gameID)
+	}
+	stages, err := rules.ParseStages(game.WinPattern)
+	if err != nil {
+		return nil, err
+	}
+	grids, err := markedGrids(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	wins, err := getWins(ctx, tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked, kept []Win
+	for _, win := range wins {
+		grid, ok := grids[win.UserID]
+		if ok && win.Stage >= 1 && win.Stage <= len(stages) && stages[win.Stage-1].Match(grid) {
+			kept = append(kept, win)
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM wins WHERE win_id = ?", win.ID); err != nil {
+			return nil, err
+		}
+		revoked = append(revoked, win)
+	}
+	if len(revoked) == 0 {
+		return nil, nil
+	}
+
+	// Renumber places in award order; winners from the same event still share a place
+	sort.SliceStable(kept, func(a, b int) bool {
+		if kept[a].Stage != kept[b].Stage {
+			return kept[a].Stage < kept[b].Stage
+		}
+		return kept[a].ID < kept[b].ID
+	})
+	for n, win := range kept {
+		place := 1
+		if n > 0 && kept[n-1].Stage == win.Stage {
+			place = kept[n-1].Place
+			if kept[n-1].EventID != win.EventID {
+				place = countStage(kept[:n], win.Stage) + 1
+			}
+		}
+		if place != win.Place {
+			if _, err := tx.ExecContext(ctx, "UPDATE wins SET place = ? WHERE win_id = ?", place, win.ID); err != nil {
+				return nil, err
+			}
+		}
+		kept[n].Place = place
+	}
+	return revoked, nil
+}
+
+// countStage counts the wins in a stage
+func countStage(wins []Win, stage int) int {
+	n := 0
+	for _, win := range wins {
+		if win.Stage == stage {
+			n++
+		}
+	}
+	return n
+}
+
+// awardedPerStage counts recorded winners in each stage
+func awardedPerStage(wins []Win, stageCount int) []int {
+	awarded := make([]int, stageCount)
+	for _, win := range wins {
+		if win.Stage >= 1 && win.Stage <= stageCount {
+			awarded[win.Stage-1]++
+		}
+	}
+	return awarded
+}
+
+// markedGrids returns every board in a game as a grid of marked squares, keyed by user ID
+func markedGrids(ctx context.Context, q querier, gameID int64) (map[int64][][]bool, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT b.user_id, b.grid_size, bs.row, bs.column, bs.kind = ? OR COALESCE(e.status = ?, 0)
+		 FROM boards b
+		 JOIN board_squares bs ON bs.board_id = b.board_id
+		 LEFT JOIN events e ON e.event_id = bs.event_id
+		 WHERE b.game_id = ?`,
+		SquareKindFree, EventStatusClosed, gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grids := make(map[int64][][]bool)
+	for rows.Next() {
+		var userID int64
+		var gridSize, row, col int
+		var marked bool
+		if err := rows.Scan(&userID, &gridSize, &row, &col, &marked); err != nil {
+			return nil, err
+		}
+		grid, ok := grids[userID]
+		if !ok {
+			grid = make([][]bool, gridSize)
+			for i := range grid {
+				grid[i] = make([]bool, gridSize)
+			}
+			grids[userID] = grid
+		}
+		grid[row][col] = marked
+	}
+	return grids, rows.Err()
+}
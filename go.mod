@@ -7,6 +7,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/fogleman/gg v1.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fordtom/bingo/bot"
@@ -36,18 +37,31 @@ func init() {
 	_ = godotenv.Load()
 }
 
+// loadEnv returns the bot token and the optional legacy CHANNEL_ID.
+// CHANNEL_ID is only used to adopt games created before per-channel scoping.
 func loadEnv() (string, string) {
 	token := os.Getenv("DISCORD_TOKEN")
 	if token == "" {
 		log.Fatal("DISCORD_TOKEN is not set")
 	}
 
-	channelID := os.Getenv("CHANNEL_ID")
-	if channelID == "" {
-		log.Fatal("CHANNEL_ID is not set")
-	}
+	return token, os.Getenv("CHANNEL_ID")
+}
+
+// defaultDeletedGameRetention is how long deleted games can be restored before they are purged
+const defaultDeletedGameRetention = 30 * 24 * time.Hour
 
-	return token, channelID
+// loadRetention reads DELETED_GAME_RETENTION, a Go duration such as "720h"
+func loadRetention() time.Duration {
+	value := os.Getenv("DELETED_GAME_RETENTION")
+	if value == "" {
+		return defaultDeletedGameRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		log.Fatalf("DELETED_GAME_RETENTION must be a duration like 720h, got %q", value)
+	}
+	return retention
 }
 
 func main() {
@@ -57,7 +71,8 @@ func main() {
 	}
 	defer cleanup()
 
-	discordToken, channelID := loadEnv()
+	discordToken, legacyChannelID := loadEnv()
+	retention := loadRetention()
 
 	// Initialize database
 	database, err := db.InitDB()
@@ -80,7 +95,7 @@ func main() {
 	defer session.Close()
 
 	var botCleanup func()
-	_, botCleanup, err = bot.Setup(session, channelID, database)
+	_, botCleanup, err = bot.Setup(session, legacyChannelID, retention, database)
 	if err != nil {
 		log.Fatal("Error setting up bot: ", err)
 	}
@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ConsensusKind identifies how many votes close an event
+type ConsensusKind string
+
+const (
+	ConsensusDefault ConsensusKind = "default" // 100% for ≤3 players, else 60%
+	ConsensusFixed   ConsensusKind = "fixed"   // a fixed number of votes
+	ConsensusPercent ConsensusKind = "percent" // a percentage of players
+	ConsensusActive  ConsensusKind = "active"  // a percentage of players who have voted in the game
+	ConsensusHost    ConsensusKind = "host"    // the host's vote confirms the event
+)
+
+// DefaultConsensus is used for games that don't choose a rule
+const DefaultConsensus = "default"
+
+// Consensus is the rule deciding when enough votes have been cast to close an event
+type Consensus struct {
+	Kind  ConsensusKind
+	Value int // vote count for fixed, percentage for percent and active
+}
+
+// Tally holds the counts a consensus rule is evaluated against for one event
+type Tally struct {
+	Votes        int  // votes cast on the event by eligible voters
+	Spectators   int  // votes cast by spectators, under open eligibility
+	Players      int  // players in the game
+	ActiveVoters int  // distinct eligible voters who have voted on any event in the game
+	HostVoted    bool // whether the game's host has voted on the event
+}
+
+// ConsensusSpecs lists one example of each rule, for help text and autocomplete
+var ConsensusSpecs = []string{"default", "percent:60", "active:50", "fixed:3", "any_two", "host"}
+
+// ParseConsensus reads a consensus spec: default, fixed:N, percent:P, active:P,
+// host, or any_two (an alias for fixed:2)
+func ParseConsensus(spec string) (Consensus, error) {
+	lower := strings.ToLower(strings.TrimSpace(spec))
+	switch lower {
+	case "", "default":
+		return Consensus{Kind: ConsensusDefault}, nil
+	case "host":
+		return Consensus{Kind: ConsensusHost}, nil
+	case "any_two", "any two", "anytwo":
+		return Consensus{Kind: ConsensusFixed, Value: 2}, nil
+	}
+
+	kind, value, ok := strings.Cut(lower, ":")
+	if !ok {
+		return Consensus{}, fmt.Errorf("unknown consensus rule %q: use default, fixed:N, percent:P, active:P, host or any_two", spec)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if err != nil {
+		return Consensus{}, fmt.Errorf("invalid number in consensus rule %q", spec)
+	}
+
+	switch ConsensusKind(kind) {
+	case ConsensusFixed:
+		if n < 1 {
+			return Consensus{}, fmt.Errorf("fixed consensus needs at least 1 vote")
+		}
+		return Consensus{Kind: ConsensusFixed, Value: n}, nil
+	case ConsensusPercent, ConsensusActive:
+		if n < 1 || n > 100 {
+			return Consensus{}, fmt.Errorf("consensus percentage must be between 1 and 100")
+		}
+		return Consensus{Kind: ConsensusKind(kind), Value: n}, nil
+	}
+	return Consensus{}, fmt.Errorf("unknown consensus rule %q: use default, fixed:N, percent:P, active:P, host or any_two", spec)
+}
+
+// String returns the spec that ParseConsensus reads back into c
+func (c Consensus) String() string {
+	switch c.Kind {
+	case ConsensusFixed, ConsensusPercent, ConsensusActive:
+		return fmt.Sprintf("%s:%d", c.Kind, c.Value)
+	case ConsensusHost:
+		return string(c.Kind)
+	}
+	return DefaultConsensus
+}
+
+// Describe returns a short human-readable explanation of the rule
+func (c Consensus) Describe() string {
+	switch c.Kind {
+	case ConsensusFixed:
+		if c.Value == 2 {
+			return "any two players"
+		}
+		if c.Value == 1 {
+			return "any single vote"
+		}
+		return fmt.Sprintf("%d votes", c.Value)
+	case ConsensusPercent:
+		return fmt.Sprintf("%d%% of players", c.Value)
+	case ConsensusActive:
+		return fmt.Sprintf("%d%% of players who have voted this game", c.Value)
+	case ConsensusHost:
+		return "host confirmation"
+	}
+	return "100% of players for ≤3 players, 60% for larger games"
+}
+
+// Validate checks that the rule can be met when the given number of people can
+// vote. Only a fixed vote count can ask for more votes than there are voters.
+func (c Consensus) Validate(voters int) error {
+	if c.Kind == ConsensusFixed && c.Value > voters {
+		return fmt.Errorf("%s needs %d votes but only %d people can vote, so events could never close", c, c.Value, voters)
+	}
+	return nil
+}
+
+// Threshold returns the number of votes needed to close an event.
+// Host confirmation needs a single vote, which must be the host's.
+func (c Consensus) Threshold(t Tally) int {
+	var threshold int
+	switch c.Kind {
+	case ConsensusFixed:
+		threshold = c.Value
+	case ConsensusPercent:
+		threshold = percentOf(c.Value, t.Players)
+	case ConsensusActive:
+		threshold = percentOf(c.Value, t.ActiveVoters)
+	case ConsensusHost:
+		threshold = 1
+	default:
+		threshold = t.Players
+		if t.Players > 3 {
+			threshold = percentOf(60, t.Players)
+		}
+	}
+	return max(threshold, 1)
+}
+
+// Reached reports whether the tally satisfies the rule
+func (c Consensus) Reached(t Tally) bool {
+	if c.Kind == ConsensusHost {
+		return t.HostVoted
+	}
+	return t.Votes >= c.Threshold(t)
+}
+
+// Progress summarises a tally for display, e.g. "2/4 votes"
+func (c Consensus) Progress(t Tally) string {
+	if c.Kind == ConsensusHost {
+		return fmt.Sprintf("%d votes, awaiting host", t.Votes)
+	}
+	return fmt.Sprintf("%d/%d votes", t.Votes, c.Threshold(t))
+}
+
+// percentOf returns ceil(pct% of n)
+func percentOf(pct, n int) int {
+	return int(math.Ceil(float64(pct) * float64(n) / 100))
+}
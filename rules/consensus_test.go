@@ -0,0 +1,125 @@
+package rules
+
+import "testing"
+
+func TestParseConsensus(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string // String() of the parsed rule
+		wantErr bool
+	}{
+		{spec: "", want: "default"},
+		{spec: "Default", want: "default"},
+		{spec: "host", want: "host"},
+		{spec: "any_two", want: "fixed:2"},
+		{spec: "any two", want: "fixed:2"},
+		{spec: "fixed:3", want: "fixed:3"},
+		{spec: "percent:60", want: "percent:60"},
+		{spec: "percent: 75%", want: "percent:75"},
+		{spec: "active:50", want: "active:50"},
+		{spec: "fixed:0", wantErr: true},
+		{spec: "percent:0", wantErr: true},
+		{spec: "percent:101", wantErr: true},
+		{spec: "active:many", wantErr: true},
+		{spec: "majority", wantErr: true},
+		{spec: "quorum:3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := ParseConsensus(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseConsensus(%q) = %v, want error", tt.spec, c)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConsensus(%q): %v", tt.spec, err)
+			}
+			if got := c.String(); got != tt.want {
+				t.Errorf("ParseConsensus(%q).String() = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsensusThreshold(t *testing.T) {
+	tests := []struct {
+		spec  string
+		tally Tally
+		want  int
+	}{
+		{"default", Tally{Players: 1}, 1},
+		{"default", Tally{Players: 3}, 3},
+		{"default", Tally{Players: 4}, 3},
+		{"default", Tally{Players: 10}, 6},
+		{"default", Tally{Players: 0}, 1},
+		{"fixed:2", Tally{Players: 10}, 2},
+		{"percent:50", Tally{Players: 5}, 3},
+		{"percent:100", Tally{Players: 7}, 7},
+		{"percent:10", Tally{Players: 0}, 1},
+		{"active:50", Tally{Players: 10, ActiveVoters: 3}, 2},
+		{"active:50", Tally{Players: 10}, 1},
+		{"host", Tally{Players: 10}, 1},
+	}
+	for _, tt := range tests {
+		c, err := ParseConsensus(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseConsensus(%q): %v", tt.spec, err)
+		}
+		if got := c.Threshold(tt.tally); got != tt.want {
+			t.Errorf("%q.Threshold(%+v) = %d, want %d", tt.spec, tt.tally, got, tt.want)
+		}
+	}
+}
+
+func TestConsensusReached(t *testing.T) {
+	tests := []struct {
+		spec  string
+		tally Tally
+		want  bool
+	}{
+		{"default", Tally{Votes: 2, Players: 3}, false},
+		{"default", Tally{Votes: 3, Players: 3}, true},
+		{"default", Tally{Votes: 3, Players: 5}, true},
+		{"fixed:2", Tally{Votes: 1, Players: 5}, false},
+		{"fixed:2", Tally{Votes: 2, Players: 5}, true},
+		{"percent:60", Tally{Votes: 2, Players: 4}, false},
+		{"percent:60", Tally{Votes: 3, Players: 4}, true},
+		{"host", Tally{Votes: 5, Players: 5}, false},
+		{"host", Tally{Votes: 1, Players: 5, HostVoted: true}, true},
+	}
+	for _, tt := range tests {
+		c, err := ParseConsensus(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseConsensus(%q): %v", tt.spec, err)
+		}
+		if got := c.Reached(tt.tally); got != tt.want {
+			t.Errorf("%q.Reached(%+v) = %t, want %t", tt.spec, tt.tally, got, tt.want)
+		}
+	}
+}
+
+func TestConsensusValidate(t *testing.T) {
+	tests := []struct {
+		spec    string
+		voters  int
+		wantErr bool
+	}{
+		{"fixed:4", 4, false},
+		{"fixed:10", 4, true},
+		{"any_two", 1, true},
+		{"percent:100", 1, false},
+		{"default", 1, false},
+		{"host", 1, false},
+	}
+	for _, tt := range tests {
+		c, err := ParseConsensus(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseConsensus(%q): %v", tt.spec, err)
+		}
+		if err := c.Validate(tt.voters); (err != nil) != tt.wantErr {
+			t.Errorf("%q.Validate(%d) = %v, want error %t", tt.spec, tt.voters, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EligibilityKind identifies who may vote on a game's events
+type EligibilityKind string
+
+const (
+	EligibilityPlayers  EligibilityKind = "players"  // only players with a board
+	EligibilityReferees EligibilityKind = "referees" // players plus named referees
+	EligibilityOpen     EligibilityKind = "open"     // anyone; spectators have their own quorum
+)
+
+// DefaultEligibility is used for games that don't choose a rule
+const DefaultEligibility = "players"
+
+// DefaultSpectatorQuorum is the spectator quorum for "open" without a number
+const DefaultSpectatorQuorum = 3
+
+// Eligibility is the rule deciding whose votes count. The host may always
+// vote, and their vote counts alongside the players'.
+type Eligibility struct {
+	Kind   EligibilityKind
+	Quorum int // spectator votes that close an event on their own, for open
+}
+
+// EligibilitySpecs lists one example of each rule, for help text and autocomplete
+var EligibilitySpecs = []string{"players", "referees", "open:3"}
+
+// ParseEligibility reads an eligibility spec: players, referees, or open:N
+// where N spectator votes close an event regardless of the players' consensus
+func ParseEligibility(spec string) (Eligibility, error) {
+	lower := strings.ToLower(strings.TrimSpace(spec))
+	switch lower {
+	case "", "players":
+		return Eligibility{Kind: EligibilityPlayers}, nil
+	case "referees":
+		return Eligibility{Kind: EligibilityReferees}, nil
+	case "open":
+		return Eligibility{Kind: EligibilityOpen, Quorum: DefaultSpectatorQuorum}, nil
+	}
+
+	kind, value, ok := strings.Cut(lower, ":")
+	if !ok || EligibilityKind(kind) != EligibilityOpen {
+		return Eligibility{}, fmt.Errorf("unknown voting rule %q: use players, referees or open:N", spec)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 {
+		return Eligibility{}, fmt.Errorf("invalid spectator quorum in %q: use open:N with N ≥ 1", spec)
+	}
+	return Eligibility{Kind: EligibilityOpen, Quorum: n}, nil
+}
+
+// String returns the spec that ParseEligibility reads back into e
+func (e Eligibility) String() string {
+	switch e.Kind {
+	case EligibilityReferees:
+		return string(e.Kind)
+	case EligibilityOpen:
+		return fmt.Sprintf("%s:%d", e.Kind, e.Quorum)
+	}
+	return DefaultEligibility
+}
+
+// Describe returns a short human-readable explanation of the rule
+func (e Eligibility) Describe() string {
+	switch e.Kind {
+	case EligibilityReferees:
+		return "players and referees"
+	case EligibilityOpen:
+		return fmt.Sprintf("anyone; %d spectator votes also close an event", e.Quorum)
+	}
+	return "players only"
+}
+
+// QuorumReached reports whether spectators have closed the event on their own
+func (e Eligibility) QuorumReached(t Tally) bool {
+	return e.Kind == EligibilityOpen && t.Spectators >= e.Quorum
+}
+
+// Progress summarises the spectator tally for display, or "" when spectators can't vote
+func (e Eligibility) Progress(t Tally) string {
+	if e.Kind != EligibilityOpen {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d spectator votes", t.Spectators, e.Quorum)
+}
@@ -0,0 +1,67 @@
+package rules
+
+import "testing"
+
+func TestParseEligibility(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string // String() of the parsed rule
+		wantErr bool
+	}{
+		{spec: "", want: "players"},
+		{spec: "players", want: "players"},
+		{spec: " Referees ", want: "referees"},
+		{spec: "open", want: "open:3"},
+		{spec: "open:5", want: "open:5"},
+		{spec: "OPEN: 2", want: "open:2"},
+		{spec: "open:0", wantErr: true},
+		{spec: "open:lots", wantErr: true},
+		{spec: "referees:2", wantErr: true},
+		{spec: "everyone", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			e, err := ParseEligibility(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseEligibility(%q) = %v, want error", tt.spec, e)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEligibility(%q): %v", tt.spec, err)
+			}
+			if got := e.String(); got != tt.want {
+				t.Errorf("ParseEligibility(%q).String() = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEligibilityQuorum(t *testing.T) {
+	tests := []struct {
+		spec         string
+		spectators   int
+		wantReached  bool
+		wantProgress string
+	}{
+		{"players", 10, false, ""},
+		{"referees", 10, false, ""},
+		{"open", 2, false, "2/3 spectator votes"},
+		{"open", 3, true, "3/3 spectator votes"},
+		{"open:1", 1, true, "1/1 spectator votes"},
+	}
+	for _, tt := range tests {
+		e, err := ParseEligibility(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseEligibility(%q): %v", tt.spec, err)
+		}
+		tally := Tally{Spectators: tt.spectators}
+		if got := e.QuorumReached(tally); got != tt.wantReached {
+			t.Errorf("%q.QuorumReached(%d spectators) = %t, want %t", tt.spec, tt.spectators, got, tt.wantReached)
+		}
+		if got := e.Progress(tally); got != tt.wantProgress {
+			t.Errorf("%q.Progress(%d spectators) = %q, want %q", tt.spec, tt.spectators, got, tt.wantProgress)
+		}
+	}
+}
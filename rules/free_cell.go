@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseFreeCell reads where every board's free square goes: "" or "none" for no
+// free square, "center" for the middle of an odd-sized grid, or "row,col"
+// counted from 1 (e.g. "2,3").
+func ParseFreeCell(spec string, gridSize int) (*Cell, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "", "none":
+		return nil, nil
+	case "center", "centre":
+		if gridSize%2 == 0 {
+			return nil, fmt.Errorf("a %dx%d grid has no center square; pick one as row,col", gridSize, gridSize)
+		}
+		return &Cell{gridSize / 2, gridSize / 2}, nil
+	}
+
+	rowSpec, colSpec, ok := strings.Cut(spec, ",")
+	if !ok {
+		return nil, fmt.Errorf("free cell %q should be center, none or row,col", spec)
+	}
+	row, rowErr := strconv.Atoi(strings.TrimSpace(rowSpec))
+	col, colErr := strconv.Atoi(strings.TrimSpace(colSpec))
+	if rowErr != nil || colErr != nil {
+		return nil, fmt.Errorf("free cell %q should be center, none or row,col", spec)
+	}
+	if row < 1 || row > gridSize || col < 1 || col > gridSize {
+		return nil, fmt.Errorf("free cell %d,%d is outside a %dx%d grid", row, col, gridSize, gridSize)
+	}
+	return &Cell{row - 1, col - 1}, nil
+}
+
+// String formats a cell as ParseFreeCell reads it, counting from 1
+func (c Cell) String() string {
+	return fmt.Sprintf("%d,%d", c.Row+1, c.Col+1)
+}
@@ -0,0 +1,385 @@
+// Package rules holds the pure game rules shared by the database and command layers.
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatternKind identifies a family of winning patterns
+type PatternKind string
+
+const (
+	PatternLine     PatternKind = "line"     // any row, column or diagonal
+	PatternLines    PatternKind = "lines"    // N distinct lines
+	PatternBlackout PatternKind = "blackout" // every square
+	PatternCorners  PatternKind = "corners"  // the four corners
+	PatternX        PatternKind = "x"        // both diagonals
+	PatternPlus     PatternKind = "plus"     // middle row and middle column
+	PatternCustom   PatternKind = "custom"   // user-supplied mask
+)
+
+// DefaultPattern is used for games that don't choose one
+const DefaultPattern = "line"
+
+// Pattern is a win condition that a board's marked squares can satisfy
+type Pattern struct {
+	Kind  PatternKind
+	Lines int      // number of lines required, for PatternLines
+	Mask  [][]bool // required squares, for PatternCustom
+}
+
+// Cell addresses a square on a board
+type Cell struct {
+	Row, Col int
+}
+
+// ParsePattern reads a pattern spec: line, lines:N, blackout, corners, x, plus,
+// or a custom mask of rows separated by "/" with X for required squares
+// (e.g. "X...X/.X.X./..X../.X.X./X...X"), optionally prefixed with "custom:".
+func ParsePattern(spec string) (Pattern, error) {
+	spec = strings.TrimSpace(spec)
+	lower := strings.ToLower(spec)
+
+	switch {
+	case lower == "" || lower == "line":
+		return Pattern{Kind: PatternLine}, nil
+	case lower == "blackout" || lower == "full house":
+		return Pattern{Kind: PatternBlackout}, nil
+	case lower == "corners" || lower == "four corners":
+		return Pattern{Kind: PatternCorners}, nil
+	case lower == "x":
+		return Pattern{Kind: PatternX}, nil
+	case lower == "plus" || lower == "+":
+		return Pattern{Kind: PatternPlus}, nil
+	case strings.HasPrefix(lower, "lines:"):
+		n, err := strconv.Atoi(strings.TrimSpace(lower[len("lines:"):]))
+		if err != nil || n < 1 {
+			return Pattern{}, fmt.Errorf("invalid line count in %q: use lines:N with N ≥ 1", spec)
+		}
+		if n == 1 {
+			return Pattern{Kind: PatternLine}, nil
+		}
+		return Pattern{Kind: PatternLines, Lines: n}, nil
+	case strings.HasPrefix(lower, "custom:") || strings.Contains(spec, "/"):
+		return parseMask(strings.TrimPrefix(lower, "custom:"))
+	}
+	return Pattern{}, fmt.Errorf("unknown win pattern %q: use line, lines:N, blackout, corners, x, plus or a mask like X.X/.X./X.X", spec)
+}
+
+// ParseStages reads a win progression: pattern specs separated by ">" that are
+// played in order, like a bingo hall's "line > lines:2 > blackout". A single
+// pattern is a one-stage game.
+func ParseStages(spec string) ([]Pattern, error) {
+	parts := strings.Split(spec, ">")
+	stages := make([]Pattern, 0, len(parts))
+	for n, part := range parts {
+		pattern, err := ParsePattern(part)
+		if err != nil {
+			if len(parts) > 1 {
+				return nil, fmt.Errorf("stage %d: %w", n+1, err)
+			}
+			return nil, err
+		}
+		stages = append(stages, pattern)
+	}
+	return stages, nil
+}
+
+// FormatStages returns the spec that ParseStages reads back into stages
+func FormatStages(stages []Pattern) string {
+	specs := make([]string, len(stages))
+	for n, pattern := range stages {
+		specs[n] = pattern.String()
+	}
+	return strings.Join(specs, " > ")
+}
+
+// CurrentStage returns the index of the first stage that still has places to
+// award, or len(awarded) once every stage is complete. awarded[i] counts the
+// winners recorded for stage i.
+func CurrentStage(awarded []int, places int) int {
+	for stage, n := range awarded {
+		if n < places {
+			return stage
+		}
+	}
+	return len(awarded)
+}
+
+// parseMask reads "/"-separated rows where X, # or 1 marks a required square and . or 0 an optional one
+func parseMask(spec string) (Pattern, error) {
+	rows := strings.Split(strings.TrimSpace(spec), "/")
+	mask := make([][]bool, len(rows))
+	required := 0
+	for r, row := range rows {
+		row = strings.TrimSpace(row)
+		if len(row) != len(rows) {
+			return Pattern{}, fmt.Errorf("custom mask must be square: row %d has %d squares, expected %d", r+1, len(row), len(rows))
+		}
+		mask[r] = make([]bool, len(row))
+		for c, ch := range row {
+			switch ch {
+			case 'x', '#', '1':
+				mask[r][c] = true
+				required++
+			case '.', '0', '-', '_', 'o':
+			default:
+				return Pattern{}, fmt.Errorf("custom mask row %d: unexpected %q (use X for required squares and . for the rest)", r+1, ch)
+			}
+		}
+	}
+	if required == 0 {
+		return Pattern{}, fmt.Errorf("custom mask has no required squares")
+	}
+	return Pattern{Kind: PatternCustom, Mask: mask}, nil
+}
+
+// String returns the spec that ParsePattern reads back into p
+func (p Pattern) String() string {
+	switch p.Kind {
+	case PatternLines:
+		return fmt.Sprintf("lines:%d", p.Lines)
+	case PatternCustom:
+		rows := make([]string, len(p.Mask))
+		for r, row := range p.Mask {
+			var b strings.Builder
+			for _, required := range row {
+				if required {
+					b.WriteByte('X')
+				} else {
+					b.WriteByte('.')
+				}
+			}
+			rows[r] = b.String()
+		}
+		return strings.Join(rows, "/")
+	case "":
+		return DefaultPattern
+	}
+	return string(p.Kind)
+}
+
+// Describe returns a short human-readable name for the pattern
+func (p Pattern) Describe() string {
+	switch p.Kind {
+	case PatternLines:
+		return fmt.Sprintf("%d lines", p.Lines)
+	case PatternBlackout:
+		return "blackout"
+	case PatternCorners:
+		return "four corners"
+	case PatternX:
+		return "X"
+	case PatternPlus:
+		return "plus"
+	case PatternCustom:
+		return "custom pattern"
+	}
+	return "any line"
+}
+
+// Validate checks that the pattern can be completed on a board of the given size
+func (p Pattern) Validate(gridSize int) error {
+	switch p.Kind {
+	case PatternLines:
+		if available := len(Lines(gridSize)); p.Lines > available {
+			return fmt.Errorf("a %dx%d board only has %d lines, so %d lines can never be completed", gridSize, gridSize, available, p.Lines)
+		}
+	case PatternPlus:
+		if gridSize%2 == 0 || gridSize < 3 {
+			return fmt.Errorf("the plus pattern needs an odd grid size of at least 3")
+		}
+	case PatternX:
+		if gridSize < 3 {
+			return fmt.Errorf("the X pattern needs a grid size of at least 3")
+		}
+	case PatternCustom:
+		if len(p.Mask) != gridSize {
+			return fmt.Errorf("custom mask is %dx%d but the board is %dx%d", len(p.Mask), len(p.Mask), gridSize, gridSize)
+		}
+	}
+	return nil
+}
+
+// Target returns the squares a fixed-shape pattern requires, or nil for
+// line-based patterns where any of several lines will do.
+func (p Pattern) Target(gridSize int) [][]bool {
+	var target [][]bool
+	set := func(row, col int) {
+		if target == nil {
+			target = newGrid(gridSize)
+		}
+		target[row][col] = true
+	}
+	last := gridSize - 1
+
+	switch p.Kind {
+	case PatternBlackout:
+		for row := range gridSize {
+			for col := range gridSize {
+				set(row, col)
+			}
+		}
+	case PatternCorners:
+		set(0, 0)
+		set(0, last)
+		set(last, 0)
+		set(last, last)
+	case PatternX:
+		for i := range gridSize {
+			set(i, i)
+			set(i, last-i)
+		}
+	case PatternPlus:
+		mid := gridSize / 2
+		for i := range gridSize {
+			set(mid, i)
+			set(i, mid)
+		}
+	case PatternCustom:
+		for row := range min(gridSize, len(p.Mask)) {
+			for col := range min(gridSize, len(p.Mask[row])) {
+				if p.Mask[row][col] {
+					set(row, col)
+				}
+			}
+		}
+	}
+	return target
+}
+
+// Match reports whether the marked squares satisfy the pattern
+func (p Pattern) Match(marked [][]bool) bool {
+	return p.Winning(marked) != nil
+}
+
+// Winning returns the marked squares that complete the pattern, or nil if it
+// isn't complete. For line patterns this is every completed line.
+func (p Pattern) Winning(marked [][]bool) []Cell {
+	gridSize := len(marked)
+
+	if target := p.Target(gridSize); target != nil {
+		var cells []Cell
+		for row := range gridSize {
+			for col := range gridSize {
+				if !target[row][col] {
+					continue
+				}
+				if !marked[row][col] {
+					return nil
+				}
+				cells = append(cells, Cell{row, col})
+			}
+		}
+		return cells
+	}
+
+	needed := 1
+	if p.Kind == PatternLines {
+		needed = p.Lines
+	}
+	var cells []Cell
+	complete := 0
+	for _, line := range CompletedLines(marked) {
+		complete++
+		cells = append(cells, line...)
+	}
+	if complete < needed {
+		return nil
+	}
+	return cells
+}
+
+// Lines returns every row, column and both diagonals of a board
+func Lines(gridSize int) [][]Cell {
+	lines := make([][]Cell, 0, 2*gridSize+2)
+	for row := range gridSize {
+		line := make([]Cell, gridSize)
+		for col := range gridSize {
+			line[col] = Cell{row, col}
+		}
+		lines = append(lines, line)
+	}
+	for col := range gridSize {
+		line := make([]Cell, gridSize)
+		for row := range gridSize {
+			line[row] = Cell{row, col}
+		}
+		lines = append(lines, line)
+	}
+	diag := make([]Cell, gridSize)
+	anti := make([]Cell, gridSize)
+	for i := range gridSize {
+		diag[i] = Cell{i, i}
+		anti[i] = Cell{i, gridSize - 1 - i}
+	}
+	return append(lines, diag, anti)
+}
+
+// CompletedLines returns the lines whose squares are all marked
+func CompletedLines(marked [][]bool) [][]Cell {
+	var complete [][]Cell
+	for _, line := range Lines(len(marked)) {
+		if countMarked(marked, line) == len(line) {
+			complete = append(complete, line)
+		}
+	}
+	return complete
+}
+
+// ScoringLines returns the lines that count toward the pattern: every line for
+// line-based patterns, and for fixed shapes the lines lying wholly inside them,
+// like the diagonals of an X. A blackout needs every square, so no line stands out.
+func (p Pattern) ScoringLines(gridSize int) [][]Cell {
+	target := p.Target(gridSize)
+	if target == nil {
+		return Lines(gridSize)
+	}
+	if p.Kind == PatternBlackout {
+		return nil
+	}
+	var lines [][]Cell
+	for _, line := range Lines(gridSize) {
+		inside := true
+		for _, c := range line {
+			inside = inside && target[c.Row][c.Col]
+		}
+		if inside {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// LinesMissing returns the lines with exactly n squares left unmarked
+func LinesMissing(marked [][]bool, lines [][]Cell, n int) [][]Cell {
+	var missing [][]Cell
+	for _, line := range lines {
+		if len(line)-countMarked(marked, line) == n {
+			missing = append(missing, line)
+		}
+	}
+	return missing
+}
+
+// countMarked counts the marked squares among cells
+func countMarked(marked [][]bool, cells []Cell) int {
+	n := 0
+	for _, c := range cells {
+		if marked[c.Row][c.Col] {
+			n++
+		}
+	}
+	return n
+}
+
+// newGrid allocates an all-false square grid
+func newGrid(gridSize int) [][]bool {
+	grid := make([][]bool, gridSize)
+	for i := range grid {
+		grid[i] = make([]bool, gridSize)
+	}
+	return grid
+}
@@ -0,0 +1,228 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+// grid builds a marked grid from "/"-separated rows where X is marked
+func grid(spec string) [][]bool {
+	rows := strings.Split(spec, "/")
+	marked := make([][]bool, len(rows))
+	for r, row := range rows {
+		marked[r] = make([]bool, len(row))
+		for c, ch := range row {
+			marked[r][c] = ch == 'X'
+		}
+	}
+	return marked
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string // String() of the parsed pattern
+		wantErr bool
+	}{
+		{spec: "", want: "line"},
+		{spec: "line", want: "line"},
+		{spec: " Line ", want: "line"},
+		{spec: "lines:1", want: "line"},
+		{spec: "lines:3", want: "lines:3"},
+		{spec: "lines:0", wantErr: true},
+		{spec: "lines:two", wantErr: true},
+		{spec: "full house", want: "blackout"},
+		{spec: "four corners", want: "corners"},
+		{spec: "X", want: "x"},
+		{spec: "+", want: "plus"},
+		{spec: "X.X/.X./X.X", want: "X.X/.X./X.X"},
+		{spec: "custom:#0#/010/#0#", want: "X.X/.X./X.X"},
+		{spec: "X.X/.X.", wantErr: true},
+		{spec: ".../.../...", wantErr: true},
+		{spec: "X?X/.X./X.X", wantErr: true},
+		{spec: "diagonal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			p, err := ParsePattern(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePattern(%q) = %v, want error", tt.spec, p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePattern(%q): %v", tt.spec, err)
+			}
+			if got := p.String(); got != tt.want {
+				t.Errorf("ParsePattern(%q).String() = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternValidate(t *testing.T) {
+	tests := []struct {
+		spec     string
+		gridSize int
+		wantErr  bool
+	}{
+		{"line", 1, false},
+		{"lines:12", 5, false},
+		{"lines:13", 5, true},
+		{"plus", 5, false},
+		{"plus", 4, true},
+		{"x", 2, true},
+		{"X.X/.X./X.X", 3, false},
+		{"X.X/.X./X.X", 5, true},
+	}
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.spec)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q): %v", tt.spec, err)
+		}
+		if err := p.Validate(tt.gridSize); (err != nil) != tt.wantErr {
+			t.Errorf("%q.Validate(%d) = %v, want error %t", tt.spec, tt.gridSize, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		spec   string
+		marked string
+		want   bool
+	}{
+		{"line", ".../.../...", false},
+		{"line", "XXX/.../...", true},
+		{"line", "X../X../X..", true},
+		{"line", "X../.X./..X", true},
+		{"line", "..X/.X./X..", true},
+		{"line", "XX./X.X/.XX", false},
+		{"lines:2", "XXX/.../...", false},
+		{"lines:2", "XXX/X../X..", true},
+		{"blackout", "XXX/XXX/XX.", false},
+		{"blackout", "XXX/XXX/XXX", true},
+		{"corners", "X.X/.../X.X", true},
+		{"corners", "X.X/.../X..", false},
+		{"x", "X.X/.X./X.X", true},
+		{"x", "X.X/.X./X..", false},
+		{"plus", ".X./XXX/.X.", true},
+		{"plus", ".X./XX./.X.", false},
+		{".X./X.X/.X.", ".X./X.X/.X.", true},
+		{".X./X.X/.X.", ".X./X../.X.", false},
+	}
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.spec)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q): %v", tt.spec, err)
+		}
+		if got := p.Match(grid(tt.marked)); got != tt.want {
+			t.Errorf("%q.Match(%s) = %t, want %t", tt.spec, tt.marked, got, tt.want)
+		}
+	}
+}
+
+func TestPatternWinning(t *testing.T) {
+	p := Pattern{Kind: PatternLine}
+	if cells := p.Winning(grid("XXX/X../X..")); len(cells) != 6 {
+		t.Errorf("Winning with a row and a column complete = %v, want both lines' 6 cells", cells)
+	}
+	if cells := p.Winning(grid("XX./.../...")); cells != nil {
+		t.Errorf("Winning with no complete line = %v, want nil", cells)
+	}
+}
+
+func TestParseStages(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string // FormatStages of the parsed stages
+		wantErr string
+	}{
+		{spec: "line", want: "line"},
+		{spec: "line > lines:2 > blackout", want: "line > lines:2 > blackout"},
+		{spec: "corners>X.X/.X./X.X", want: "corners > X.X/.X./X.X"},
+		{spec: "line > nonsense", wantErr: "stage 2:"},
+		{spec: "nonsense", wantErr: "unknown win pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			stages, err := ParseStages(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseStages(%q) error = %v, want one containing %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStages(%q): %v", tt.spec, err)
+			}
+			if got := FormatStages(stages); got != tt.want {
+				t.Errorf("FormatStages(ParseStages(%q)) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentStage(t *testing.T) {
+	tests := []struct {
+		awarded []int
+		places  int
+		want    int
+	}{
+		{[]int{0}, 3, 0},
+		{[]int{2, 0}, 3, 0},
+		{[]int{3, 0}, 3, 1},
+		{[]int{3, 1, 0}, 3, 1},
+		{[]int{1, 1}, 1, 2},
+		{[]int{4, 3}, 3, 2},
+	}
+	for _, tt := range tests {
+		if got := CurrentStage(tt.awarded, tt.places); got != tt.want {
+			t.Errorf("CurrentStage(%v, %d) = %d, want %d", tt.awarded, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestScoringLines(t *testing.T) {
+	tests := []struct {
+		spec string
+		want int // lines on a 5x5 board
+	}{
+		{"line", 12},
+		{"lines:2", 12},
+		{"blackout", 0},
+		{"corners", 0},
+		{"x", 2},
+		{"plus", 2},
+		{"XXXXX/X..../X..../X..../X....", 2},
+	}
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.spec)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q): %v", tt.spec, err)
+		}
+		if got := p.ScoringLines(5); len(got) != tt.want {
+			t.Errorf("%q.ScoringLines(5) = %d lines, want %d", tt.spec, len(got), tt.want)
+		}
+	}
+}
+
+func TestLinesMissing(t *testing.T) {
+	marked := grid("XXX/XX./X..")
+	lines := Lines(3)
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 3}, // top row, left column, anti-diagonal
+		{1, 3}, // middle row, middle column, diagonal
+		{2, 2}, // bottom row, right column
+		{3, 0},
+	}
+	for _, tt := range tests {
+		if got := LinesMissing(marked, lines, tt.n); len(got) != tt.want {
+			t.Errorf("LinesMissing(n=%d) = %v, want %d lines", tt.n, got, tt.want)
+		}
+	}
+}